@@ -0,0 +1,155 @@
+package baseline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// formatVersion はベースラインファイルの形式のバージョン
+const formatVersion = 1
+
+// Baseline は既知の構成ドリフトとして許容する差分の一覧
+type Baseline struct {
+	Version int                    `json:"version"`
+	Entries []*types.BaselineEntry `json:"entries"`
+}
+
+// Load はベースラインファイルを読み込む
+func Load(filename string) (*Baseline, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ベースラインファイルの読み込みに失敗しました:\n  ファイル: %s\n  エラー: %w\n"+
+			"ヒント: tfspec check --write-baseline %s で作成してください", filename, err, filename)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, fmt.Errorf("ベースラインファイルの解析に失敗しました:\n  ファイル: %s\n  エラー: %w", filename, err)
+	}
+	if baseline.Version != formatVersion {
+		return nil, fmt.Errorf("サポートされていないベースラインファイルのバージョンです: %d", baseline.Version)
+	}
+
+	return &baseline, nil
+}
+
+// Write は構成ドリフト（無視されていない差分）をベースラインファイルに書き込む
+func Write(filename string, diffs []*types.DiffResult) (int, error) {
+	baseline := &Baseline{Version: formatVersion, Entries: []*types.BaselineEntry{}}
+	for _, diff := range diffs {
+		if diff.IsIgnored {
+			continue
+		}
+
+		entry, err := newEntry(diff)
+		if err != nil {
+			return 0, err
+		}
+		baseline.Entries = append(baseline.Entries, entry)
+	}
+
+	// 差分の検出順に依存しないよう並べ替えて、レビューしやすいファイルにする
+	sort.Slice(baseline.Entries, func(i, j int) bool {
+		return entryKey(baseline.Entries[i]) < entryKey(baseline.Entries[j])
+	})
+
+	content, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return 0, fmt.Errorf("ディレクトリの作成に失敗しました: %w", err)
+		}
+	}
+	if err := os.WriteFile(filename, append(content, '\n'), 0644); err != nil {
+		return 0, fmt.Errorf("ベースラインファイルの書き込みに失敗しました:\n  ファイル: %s\n  エラー: %w", filename, err)
+	}
+
+	return len(baseline.Entries), nil
+}
+
+// Apply は構成ドリフトをベースラインと照合する
+// ベースラインにない差分（無視された差分を含む）、ベースラインで許容された差分、
+// 現在は検出されなくなったベースラインの項目を返す
+func (b *Baseline) Apply(diffs []*types.DiffResult) ([]*types.DiffResult, []*types.DiffResult, []*types.BaselineEntry) {
+	entryMap := make(map[string]*types.BaselineEntry)
+	for _, entry := range b.Entries {
+		entryMap[entryKey(entry)] = entry
+	}
+
+	var remaining, baselined []*types.DiffResult
+	matched := make(map[string]bool)
+	for _, diff := range diffs {
+		if !diff.IsIgnored {
+			if entry, err := newEntry(diff); err == nil {
+				if _, exists := entryMap[entryKey(entry)]; exists {
+					matched[entryKey(entry)] = true
+					baselined = append(baselined, diff)
+					continue
+				}
+			}
+		}
+		remaining = append(remaining, diff)
+	}
+
+	var stale []*types.BaselineEntry
+	for _, entry := range b.Entries {
+		if !matched[entryKey(entry)] {
+			stale = append(stale, entry)
+		}
+	}
+
+	return remaining, baselined, stale
+}
+
+// newEntry は差分からベースラインの項目を生成する
+func newEntry(diff *types.DiffResult) (*types.BaselineEntry, error) {
+	expected, err := valueJSON(diff.Expected)
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s の値を記録できませんでした: %w", diff.Resource, diff.Path, err)
+	}
+	actual, err := valueJSON(diff.Actual)
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s の値を記録できませんでした: %w", diff.Resource, diff.Path, err)
+	}
+
+	return &types.BaselineEntry{
+		Environment: diff.Environment,
+		Resource:    diff.Resource,
+		Path:        diff.Path,
+		Expected:    expected,
+		Actual:      actual,
+	}, nil
+}
+
+// valueJSON は値を型情報なしのJSONに変換する
+func valueJSON(value cty.Value) (json.RawMessage, error) {
+	if value == cty.NilVal || !value.IsWhollyKnown() {
+		return json.RawMessage("null"), nil
+	}
+	return ctyjson.SimpleJSONValue{Value: value}.MarshalJSON()
+}
+
+// entryKey はベースラインの項目を環境・リソース・属性パス・値で識別するキーを返す
+func entryKey(entry *types.BaselineEntry) string {
+	return entry.Environment + "|" + entry.Resource + "|" + entry.Path + "|" + compactJSON(entry.Expected) + "|" + compactJSON(entry.Actual)
+}
+
+// compactJSON はJSONの空白を取り除いて比較できる形にする
+func compactJSON(content json.RawMessage) string {
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, content); err != nil {
+		return string(content)
+	}
+	return buffer.String()
+}
@@ -0,0 +1,263 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// formatVersion はキャッシュの保存形式のバージョン（形式を変更した場合は上げる）
+const formatVersion = "4"
+
+// maxAge は使用されなかったキャッシュを削除するまでの期間
+const maxAge = 7 * 24 * time.Hour
+
+// Store はファイルごとの解析結果をディスクに保存するキャッシュ
+// キーはファイルの内容・tfspecのバージョン等のハッシュで、内容が変わると自動的に別のキーになる
+// 複数の環境を並行して解析する際に共有するため、各メソッドは並行して呼び出せる
+type Store struct {
+	dir     string
+	version string
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+// entry はキャッシュファイルの内容
+type entry struct {
+	// 解析時に読み込んだローカルモジュール（ネストしたモジュールを含む）のディレクトリ -> 内容のハッシュ（変更されていればキャッシュを使わない）
+	Dependencies map[string]string `json:"dependencies"`
+	Value        any               `json:"value"`
+}
+
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:     dir,
+		version: tfspecVersion(),
+	}
+}
+
+// Key はキャッシュのキーを求める（partsにはファイルの内容と解析結果に影響する値を渡す）
+func (s *Store) Key(parts ...[]byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00", formatVersion, s.version)
+	for _, part := range parts {
+		fmt.Fprintf(hash, "%d\x00", len(part))
+		hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Load はキャッシュされた内容をtarget（構造体のポインタ）に読み込む
+// 存在しない・読み込めない・依存するモジュールが変更されている場合はfalseを返す
+func (s *Store) Load(key string, target any) bool {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() {
+		return false
+	}
+
+	path := s.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	var cached entry
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return false
+	}
+	for dir, hash := range cached.Dependencies {
+		if hashDir(dir) != hash {
+			return false
+		}
+	}
+
+	// 壊れたキャッシュで値を復元できない場合も再解析する
+	if err := decodeValue(cached.Value, targetValue.Elem()); err != nil {
+		return false
+	}
+
+	// 使用したキャッシュは期限を延長する
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return true
+}
+
+// Save はvalue（構造体のポインタ）をキャッシュに保存する
+// dependencies: 解析時に読み込んだローカルモジュールのディレクトリ（ネストしたモジュールも1つずつ渡す）
+func (s *Store) Save(key string, value any, dependencies []string) error {
+	encoded, err := encodeValue(reflect.ValueOf(value))
+	if err != nil {
+		return err
+	}
+
+	cached := entry{
+		Dependencies: make(map[string]string, len(dependencies)),
+		Value:        encoded,
+	}
+	for _, dir := range dependencies {
+		cached.Dependencies[dir] = hashDir(dir)
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ensureDir(); err != nil {
+		return err
+	}
+
+	// 並行して保存しても壊れたファイルを読まないよう、一時ファイルに書いてから置き換える
+	tmp, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// Record は解析結果を再利用したかどうかを記録する（Statsで集計する）
+func (s *Store) Record(reused bool) {
+	if reused {
+		s.hits.Add(1)
+	} else {
+		s.misses.Add(1)
+	}
+}
+
+// Stats は解析結果を再利用したファイル数と解析したファイル数を返す
+func (s *Store) Stats() (hits, misses int64) {
+	return s.hits.Load(), s.misses.Load()
+}
+
+// Prune は一定期間使用されていないキャッシュを削除する
+func (s *Store) Prune() {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+
+	threshold := time.Now().Add(-maxAge)
+	for _, dirEntry := range entries {
+		if dirEntry.IsDir() || filepath.Ext(dirEntry.Name()) != ".json" {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil || info.ModTime().After(threshold) {
+			continue
+		}
+		os.Remove(filepath.Join(s.dir, dirEntry.Name()))
+	}
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+// ensureDir はキャッシュディレクトリを作成する
+// キャッシュがリポジトリにコミットされないよう、ディレクトリ内に全てを除外する.gitignoreを置く
+func (s *Store) ensureDir() error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	gitignore := filepath.Join(s.dir, ".gitignore")
+	if _, err := os.Stat(gitignore); os.IsNotExist(err) {
+		return os.WriteFile(gitignore, []byte("*\n"), 0644)
+	}
+	return nil
+}
+
+// hashDir はモジュールディレクトリ内の.tfファイルの名前と内容のハッシュを求める（存在しない場合は空文字列）
+// ネストしたモジュールは別のディレクトリとして依存関係に含まれるため、ディレクトリ直下のファイルのみを対象にする
+func hashDir(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var names []string
+	for _, dirEntry := range entries {
+		if !dirEntry.IsDir() && filepath.Ext(dirEntry.Name()) == ".tf" {
+			names = append(names, dirEntry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return ""
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", name, len(data))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+var (
+	versionOnce sync.Once
+	version     string
+)
+
+// tfspecVersion は実行中のtfspecのバージョンを返す
+// リリース版はモジュールのバージョンとコミットを使い、開発中のビルド（未コミットの変更を含む場合など）は実行ファイルのハッシュを使う
+func tfspecVersion() string {
+	versionOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if ok {
+			var revision string
+			modified := false
+			for _, setting := range info.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					revision = setting.Value
+				case "vcs.modified":
+					modified = setting.Value == "true"
+				}
+			}
+			if info.Main.Version != "" && info.Main.Version != "(devel)" {
+				version = info.Main.Version
+				return
+			}
+			if revision != "" && !modified {
+				version = revision
+				return
+			}
+		}
+		version = executableHash()
+	})
+	return version
+}
+
+// executableHash は実行ファイルのハッシュを返す（求められない場合はキャッシュを実質無効にするため起動ごとに変わる値を返す）
+func executableHash() string {
+	path, err := os.Executable()
+	if err == nil {
+		if file, err := os.Open(path); err == nil {
+			defer file.Close()
+			hash := sha256.New()
+			if _, err := io.Copy(hash, file); err == nil {
+				return hex.EncodeToString(hash.Sum(nil))
+			}
+		}
+	}
+	return fmt.Sprintf("unknown-%d", time.Now().UnixNano())
+}
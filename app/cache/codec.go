@@ -0,0 +1,387 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+var ctyValueType = reflect.TypeOf(cty.Value{})
+
+// encodeValue は解析結果の構造体をJSONに変換できる値にする
+func encodeValue(v reflect.Value) (any, error) {
+	if v.Type() == ctyValueType {
+		return encodeCtyValue(v.Interface().(cty.Value))
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encodeValue(v.Elem())
+
+	case reflect.Struct:
+		fields := make(map[string]any)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			encoded, err := encodeValue(v.Field(i))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field.Name, err)
+			}
+			fields[field.Name] = encoded
+		}
+		return fields, nil
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			encoded, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = encoded
+		}
+		return items, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("キャッシュできないマップのキーです: %s", v.Type())
+		}
+		items := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			encoded, err := encodeValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			items[iter.Key().String()] = encoded
+		}
+		return items, nil
+
+	case reflect.String, reflect.Bool:
+		return v.Interface(), nil
+
+	case reflect.Int:
+		return v.Int(), nil
+	}
+
+	return nil, fmt.Errorf("キャッシュできない型です: %s", v.Type())
+}
+
+// decodeValue はencodeValueで保存した値（json.Unmarshalでanyに読み込んだもの）を構造体に復元する
+func decodeValue(data any, target reflect.Value) error {
+	if data == nil {
+		return nil // ゼロ値のまま
+	}
+
+	if target.Type() == ctyValueType {
+		value, err := decodeCtyValue(data)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(target.Type().Elem())
+		if err := decodeValue(data, elem.Elem()); err != nil {
+			return err
+		}
+		target.Set(elem)
+		return nil
+
+	case reflect.Struct:
+		fields, ok := data.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s の値が正しくありません", target.Type())
+		}
+		for i := 0; i < target.NumField(); i++ {
+			field := target.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := decodeValue(fields[field.Name], target.Field(i)); err != nil {
+				return fmt.Errorf("%s: %w", field.Name, err)
+			}
+		}
+		return nil
+
+	case reflect.Slice:
+		items, ok := data.([]any)
+		if !ok {
+			return fmt.Errorf("%s の値が正しくありません", target.Type())
+		}
+		slice := reflect.MakeSlice(target.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+		return nil
+
+	case reflect.Map:
+		items, ok := data.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s の値が正しくありません", target.Type())
+		}
+		if target.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("キャッシュできないマップのキーです: %s", target.Type())
+		}
+		m := reflect.MakeMapWithSize(target.Type(), len(items))
+		for key, item := range items {
+			elem := reflect.New(target.Type().Elem()).Elem()
+			if err := decodeValue(item, elem); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(target.Type().Key()), elem)
+		}
+		target.Set(m)
+		return nil
+
+	case reflect.String, reflect.Bool:
+		value := reflect.ValueOf(data)
+		if value.Kind() != target.Kind() {
+			return fmt.Errorf("%s の値が正しくありません", target.Type())
+		}
+		target.Set(value.Convert(target.Type()))
+		return nil
+
+	case reflect.Int:
+		// json.Unmarshalはanyへの数値をfloat64で読み込む
+		number, ok := data.(float64)
+		if !ok {
+			return fmt.Errorf("%s の値が正しくありません", target.Type())
+		}
+		target.SetInt(int64(number))
+		return nil
+	}
+
+	return fmt.Errorf("キャッシュできない型です: %s", target.Type())
+}
+
+// encodeCtyValue はcty.Valueを [種類, 値, (要素の型)] の配列にする
+// リスト・セット・タプル、マップ・オブジェクトの違いを保持し、null・空のコレクションは型情報も保存する
+func encodeCtyValue(value cty.Value) (any, error) {
+	if value == cty.NilVal {
+		return nil, nil
+	}
+	if !value.IsKnown() || value.IsMarked() {
+		return nil, fmt.Errorf("キャッシュできない値です: %#v", value)
+	}
+
+	ty := value.Type()
+	if value.IsNull() {
+		typeJSON, err := ctyjson.MarshalType(ty)
+		if err != nil {
+			return nil, err
+		}
+		return []any{"null", json.RawMessage(typeJSON)}, nil
+	}
+
+	switch {
+	case ty == cty.String:
+		return []any{"string", value.AsString()}, nil
+	case ty == cty.Number:
+		return []any{"number", value.AsBigFloat().Text('g', -1)}, nil
+	case ty == cty.Bool:
+		return []any{"bool", value.True()}, nil
+
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		kind := "tuple"
+		if ty.IsListType() {
+			kind = "list"
+		} else if ty.IsSetType() {
+			kind = "set"
+		}
+		items := make([]any, 0, value.LengthInt())
+		for it := value.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			encoded, err := encodeCtyValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, encoded)
+		}
+		if len(items) == 0 && kind != "tuple" {
+			typeJSON, err := ctyjson.MarshalType(ty.ElementType())
+			if err != nil {
+				return nil, err
+			}
+			return []any{kind, items, json.RawMessage(typeJSON)}, nil
+		}
+		return []any{kind, items}, nil
+
+	case ty.IsMapType() || ty.IsObjectType():
+		kind := "object"
+		if ty.IsMapType() {
+			kind = "map"
+		}
+		items := make(map[string]any, value.LengthInt())
+		for it := value.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			encoded, err := encodeCtyValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			items[key.AsString()] = encoded
+		}
+		if len(items) == 0 && kind == "map" {
+			typeJSON, err := ctyjson.MarshalType(ty.ElementType())
+			if err != nil {
+				return nil, err
+			}
+			return []any{kind, items, json.RawMessage(typeJSON)}, nil
+		}
+		return []any{kind, items}, nil
+	}
+
+	return nil, fmt.Errorf("キャッシュできない型の値です: %s", ty.FriendlyName())
+}
+
+// decodeCtyValue はencodeCtyValueで保存した値をcty.Valueに復元する
+func decodeCtyValue(data any) (cty.Value, error) {
+	encoded, ok := data.([]any)
+	if !ok || len(encoded) < 2 {
+		return cty.NilVal, fmt.Errorf("値の形式が正しくありません: %v", data)
+	}
+	kind, _ := encoded[0].(string)
+
+	switch kind {
+	case "null":
+		ty, err := decodeCtyType(encoded[1])
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return cty.NullVal(ty), nil
+	case "string":
+		if s, ok := encoded[1].(string); ok {
+			return cty.StringVal(s), nil
+		}
+	case "number":
+		if s, ok := encoded[1].(string); ok {
+			return cty.ParseNumberVal(s)
+		}
+	case "bool":
+		if b, ok := encoded[1].(bool); ok {
+			return cty.BoolVal(b), nil
+		}
+
+	case "list", "set", "tuple":
+		items, ok := encoded[1].([]any)
+		if !ok {
+			break
+		}
+		if len(items) == 0 {
+			if kind == "tuple" {
+				return cty.EmptyTupleVal, nil
+			}
+			if len(encoded) < 3 {
+				break
+			}
+			elemType, err := decodeCtyType(encoded[2])
+			if err != nil {
+				return cty.NilVal, err
+			}
+			if kind == "list" {
+				return cty.ListValEmpty(elemType), nil
+			}
+			return cty.SetValEmpty(elemType), nil
+		}
+		elems := make([]cty.Value, len(items))
+		for i, item := range items {
+			elem, err := decodeCtyValue(item)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems[i] = elem
+		}
+		if kind != "tuple" {
+			if err := checkElementTypes(elems); err != nil {
+				return cty.NilVal, err
+			}
+		}
+		switch kind {
+		case "list":
+			return cty.ListVal(elems), nil
+		case "set":
+			return cty.SetVal(elems), nil
+		}
+		return cty.TupleVal(elems), nil
+
+	case "map", "object":
+		items, ok := encoded[1].(map[string]any)
+		if !ok {
+			break
+		}
+		if len(items) == 0 {
+			if kind == "object" {
+				return cty.EmptyObjectVal, nil
+			}
+			if len(encoded) < 3 {
+				break
+			}
+			elemType, err := decodeCtyType(encoded[2])
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.MapValEmpty(elemType), nil
+		}
+		elems := make(map[string]cty.Value, len(items))
+		for key, item := range items {
+			elem, err := decodeCtyValue(item)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elems[key] = elem
+		}
+		if kind == "map" {
+			values := make([]cty.Value, 0, len(elems))
+			for _, elem := range elems {
+				values = append(values, elem)
+			}
+			if err := checkElementTypes(values); err != nil {
+				return cty.NilVal, err
+			}
+			return cty.MapVal(elems), nil
+		}
+		return cty.ObjectVal(elems), nil
+	}
+
+	return cty.NilVal, fmt.Errorf("値の形式が正しくありません: %v", data)
+}
+
+// checkElementTypes はリスト・セット・マップの要素の型が全て同じかどうかを確かめる
+// cty.ListVal等は型が揃っていない場合にpanicするため、壊れたキャッシュは事前にエラーにする
+func checkElementTypes(elems []cty.Value) error {
+	for _, elem := range elems[1:] {
+		if !elem.Type().Equals(elems[0].Type()) {
+			return fmt.Errorf("要素の型が揃っていません: %s, %s", elems[0].Type().FriendlyName(), elem.Type().FriendlyName())
+		}
+	}
+	return nil
+}
+
+// decodeCtyType はctyjson.MarshalTypeで保存した型（anyに読み込んだもの）を復元する
+func decodeCtyType(data any) (cty.Type, error) {
+	typeJSON, err := json.Marshal(data)
+	if err != nil {
+		return cty.NilType, err
+	}
+	return ctyjson.UnmarshalType(typeJSON)
+}
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/Mkamono/tfspec/app/config"
 	"github.com/Mkamono/tfspec/app/service"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 type TfspecApp struct {
@@ -35,25 +41,195 @@ func (app *TfspecApp) CreateRootCommand() *cobra.Command {
 
 .tfspecignoreに記載された意図的な差分は除外され、残った差分のみが構成ドリフトとして報告されます。`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			verbose, _ := cmd.Flags().GetBool("verbose")
+			options, err := checkOptionsFromFlags(cmd, args)
+			if err != nil {
+				return err
+			}
+			return app.appService.RunCheck(options)
+		},
+	}
+	addCheckFlags(checkCmd)
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "tfspecの設定を扱います",
+	}
+
+	configShowCmd := &cobra.Command{
+		Use:   "show [環境ディレクトリ...]",
+		Short: "設定ファイルとコマンドラインのオプションを反映した有効な設定を表示します",
+		Long: `設定ファイル（.tfspec/config.hcl）とコマンドラインのオプションを反映した、checkコマンドの有効な設定を表示します。
+
+checkコマンドと同じフラグを指定でき、フラグで指定した値は設定ファイルの値より優先されます。`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options, err := checkOptionsFromFlags(cmd, args)
+			if err != nil {
+				return err
+			}
+			return app.appService.RunConfigShow(options)
+		},
+	}
+	addCheckFlags(configShowCmd)
+	configCmd.AddCommand(configShowCmd)
+
+	historyCmd := &cobra.Command{
+		Use:   "history <環境ディレクトリ>",
+		Short: "1つの環境の構成が指定したgit ref時点からどう変わったかを表示します",
+		Long: `1つの環境の構成が指定したgit ref時点からどう変わったかを表示します。
+
+指定ref時点と作業ツリーの環境ファイルを解析し、checkコマンドと同じリソース・属性パスの単位で変更を報告します。
+ファイルはローカルのgitリポジトリから読み込みます。`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			since, _ := cmd.Flags().GetString("since")
 			outputFile, _ := cmd.Flags().GetString("output")
 			outputFlag := cmd.Flags().Changed("output")
-			noFail, _ := cmd.Flags().GetBool("no-fail")
-			excludeDirs, _ := cmd.Flags().GetStringSlice("exclude-dirs")
 			maxValueLength, _ := cmd.Flags().GetInt("max-value-length")
 			trimCell, _ := cmd.Flags().GetBool("trim-cell")
-			return app.appService.RunCheck(args, verbose, outputFile, outputFlag, noFail, excludeDirs, maxValueLength, trimCell)
+
+			return app.appService.RunHistory(&config.HistoryOptions{
+				EnvDir:         args[0],
+				Since:          since,
+				OutputFile:     outputFile,
+				OutputFlag:     outputFlag,
+				MaxValueLength: maxValueLength,
+				TrimCell:       trimCell,
+			})
 		},
 	}
 
-	checkCmd.Flags().BoolP("verbose", "v", false, "詳細な差分情報を表示")
-	checkCmd.Flags().StringP("output", "o", "", "結果をMarkdownファイルに出力 (例: -o report.md, -o単体で.tfspec/report.mdに出力)")
-	checkCmd.Flags().Lookup("output").NoOptDefVal = ".tfspec/report.md"
-	checkCmd.Flags().Bool("no-fail", false, "構成ドリフトが検出されてもエラーコードで終了しない")
-	checkCmd.Flags().StringSliceP("exclude-dirs", "e", []string{}, "除外するディレクトリ名 (例: --exclude-dirs node_modules,vendor)")
-	checkCmd.Flags().Int("max-value-length", 400, "テーブルに表示する値の最大文字数 (デフォルト: 400)")
-	checkCmd.Flags().Bool("trim-cell", false, "テーブルのセル前後の余白を削除")
+	historyCmd.Flags().String("since", "HEAD", "比較の基準とするgit ref（ブランチ名・タグ・コミットハッシュ）")
+	historyCmd.Flags().StringP("output", "o", "", "結果をMarkdownファイルに出力 (例: -o history.md, -o単体で.tfspec/history.mdに出力)")
+	historyCmd.Flags().Lookup("output").NoOptDefVal = ".tfspec/history.md"
+	historyCmd.Flags().Int("max-value-length", 400, "テーブルに表示する値の最大文字数 (デフォルト: 400)")
+	historyCmd.Flags().Bool("trim-cell", false, "テーブルのセル前後の余白を削除")
 
 	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(configCmd)
 	return rootCmd
 }
+
+// addCheckFlags はcheckコマンドのフラグを登録する（config showでも同じフラグを使用する）
+func addCheckFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("verbose", "v", false, "詳細な差分情報を表示")
+	cmd.Flags().StringP("output", "o", "", "結果をMarkdownファイルに出力 (例: -o report.md, -o単体で.tfspec/report.mdに出力)")
+	cmd.Flags().Lookup("output").NoOptDefVal = ".tfspec/report.md"
+	cmd.Flags().Bool("no-fail", false, "構成ドリフトが検出されてもエラーコードで終了しない")
+	cmd.Flags().StringSlice("fail-on", []string{"drift"}, "エラー終了させる検出結果 (drift, existence-only, severity>=<重要度>, stale-rules, expired-rules, pending-changes)")
+	cmd.Flags().StringSliceP("exclude-dirs", "e", []string{}, "除外するディレクトリ名 (例: --exclude-dirs node_modules,vendor)")
+	cmd.Flags().Int("max-value-length", 400, "テーブルに表示する値の最大文字数 (デフォルト: 400)")
+	cmd.Flags().Bool("trim-cell", false, "テーブルのセル前後の余白を削除")
+	cmd.Flags().Bool("stacks", false, ".tfspecディレクトリを持つディレクトリをスタックとして検出し、スタックごとにチェックした結果を統合して報告する")
+	cmd.Flags().IntP("jobs", "j", runtime.NumCPU(), "環境を並行して解析する数 (デフォルト: CPU数)")
+	cmd.Flags().BoolP("watch", "w", false, "環境ディレクトリと.tfspec/の変更を監視し、変更のたびにチェックを再実行して前回からの差分の変化を表示する")
+	cmd.Flags().Bool("no-cache", false, ".tfspec/cache/ に保存したファイルごとの解析結果を使用せず、全ファイルを解析する")
+	cmd.Flags().Int("discovery-depth", 1, "環境ディレクトリを自動検出する階層の深さ (例: envs/<region>/<env>/ 構成では --discovery-depth 3)")
+	cmd.Flags().String("base-env", "", "他の環境の比較元とする環境名 (例: --base-env prod、省略時は環境名の順で最初の環境)")
+	cmd.Flags().StringArray("group", []string{}, "環境グループを グループ名=環境1,環境2 で指定し、グループ内とグループ間で分けて比較 (例: --group prod=prod-tokyo,prod-osaka)")
+	cmd.Flags().StringArray("state", []string{}, "HCLの代わりに比較するstateファイル（terraform show -json の出力）を 環境名=パス で指定 (例: --state prod=prod.json)")
+	cmd.Flags().StringArray("plan", []string{}, "HCLの代わりに比較するplanファイル（terraform show -json plan.out の出力）を 環境名=パス で指定 (例: --plan prod=prod-plan.json)")
+	cmd.Flags().String("base-ref", "", "指定したgit ref時点と比べて新規・変更・解消された差分のみを報告 (例: --base-ref main)")
+	cmd.Flags().String("baseline-file", "", "ベースラインファイルに記録された構成ドリフトを許容し、新しい構成ドリフトのみでエラー終了する (例: --baseline-file .tfspec/baseline.json)")
+	cmd.Flags().String("write-baseline", "", "現在の構成ドリフトをベースラインファイルに書き込む (例: --write-baseline .tfspec/baseline.json)")
+}
+
+// checkOptionsFromFlags はフラグの値からcheckコマンドのオプションを組み立てる
+func checkOptionsFromFlags(cmd *cobra.Command, args []string) (*config.CheckOptions, error) {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	outputFile, _ := cmd.Flags().GetString("output")
+	outputFlag := cmd.Flags().Changed("output")
+	noFail, _ := cmd.Flags().GetBool("no-fail")
+	excludeDirs, _ := cmd.Flags().GetStringSlice("exclude-dirs")
+	maxValueLength, _ := cmd.Flags().GetInt("max-value-length")
+	trimCell, _ := cmd.Flags().GetBool("trim-cell")
+	baseEnv, _ := cmd.Flags().GetString("base-env")
+	discoveryDepth, _ := cmd.Flags().GetInt("discovery-depth")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	watch, _ := cmd.Flags().GetBool("watch")
+	stacks, _ := cmd.Flags().GetBool("stacks")
+	stateFlags, _ := cmd.Flags().GetStringArray("state")
+	planFlags, _ := cmd.Flags().GetStringArray("plan")
+	groupFlags, _ := cmd.Flags().GetStringArray("group")
+	failOn, _ := cmd.Flags().GetStringSlice("fail-on")
+	baseRef, _ := cmd.Flags().GetString("base-ref")
+	baselineFile, _ := cmd.Flags().GetString("baseline-file")
+	writeBaseline, _ := cmd.Flags().GetString("write-baseline")
+
+	stateFiles, err := parseEnvFileFlags(stateFlags, "state")
+	if err != nil {
+		return nil, err
+	}
+	planFiles, err := parseEnvFileFlags(planFlags, "plan")
+	if err != nil {
+		return nil, err
+	}
+	groups, err := parseGroupFlags(groupFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	// 設定ファイルより優先するため、コマンドラインで指定されたフラグを記録する
+	setFlags := make(map[string]bool)
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		setFlags[flag.Name] = true
+	})
+
+	return &config.CheckOptions{
+		EnvDirs:        args,
+		Verbose:        verbose,
+		OutputFile:     outputFile,
+		OutputFlag:     outputFlag,
+		NoFail:         noFail,
+		ExcludeDirs:    excludeDirs,
+		MaxValueLength: maxValueLength,
+		TrimCell:       trimCell,
+		StateFiles:     stateFiles,
+		PlanFiles:      planFiles,
+
+		FailOn:         failOn,
+		BaseRef:        baseRef,
+		BaselineFile:   baselineFile,
+		WriteBaseline:  writeBaseline,
+		BaseEnv:        baseEnv,
+		Groups:         groups,
+		Jobs:           jobs,
+		NoCache:        noCache,
+		Watch:          watch,
+		DiscoveryDepth: discoveryDepth,
+		Stacks:         stacks,
+		SetFlags:       setFlags,
+	}, nil
+}
+
+// parseGroupFlags は グループ名=環境1,環境2 形式のフラグ値を環境グループに変換する
+func parseGroupFlags(values []string) ([]*config.Group, error) {
+	var groups []*config.Group
+	for _, value := range values {
+		name, envs, found := strings.Cut(value, "=")
+		if !found || name == "" || envs == "" {
+			return nil, fmt.Errorf("--group の形式が正しくありません: %s\n"+
+				"ヒント: --group グループ名=環境1,環境2 の形式で指定してください", value)
+		}
+		groups = append(groups, &config.Group{Name: name, Envs: strings.Split(envs, ",")})
+	}
+	return groups, nil
+}
+
+// parseEnvFileFlags は 環境名=パス 形式のフラグ値を環境名 -> パスのマップに変換する
+func parseEnvFileFlags(values []string, flagName string) (map[string]string, error) {
+	envFiles := make(map[string]string)
+	for _, value := range values {
+		envName, path, found := strings.Cut(value, "=")
+		if !found || envName == "" || path == "" {
+			return nil, fmt.Errorf("--%s の形式が正しくありません: %s\n"+
+				"ヒント: --%s 環境名=パス の形式で指定してください", flagName, value, flagName)
+		}
+		if _, exists := envFiles[envName]; exists {
+			return nil, fmt.Errorf("--%s で環境 '%s' が複数回指定されています", flagName, envName)
+		}
+		envFiles[envName] = path
+	}
+	return envFiles, nil
+}
@@ -4,15 +4,82 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // Config はアプリケーションの設定を管理する
 type Config struct {
+	BaseDir     string // 環境の検出・.tfspecディレクトリの基準ディレクトリ
 	TfspecDir   string
+	ConfigFile  string // 読み込んだ設定ファイル（ない場合は空）
 	EnvDirs     []string
 	Verbose     bool
 	NoFail      bool
 	ExcludeDirs []string
+	StateFiles  map[string]string // 環境名 -> stateファイル（指定時はHCLの代わりにstateを比較する）
+	PlanFiles   map[string]string // 環境名 -> planファイル（指定時はHCLの代わりにplanを比較する）
+
+	EnvNames       map[string]string // 環境ディレクトリ -> 環境名（ディレクトリのパスから決定する）
+	BaseEnv        string            // 他の環境の比較元とする環境（未指定の場合は環境名の順で最初の環境）
+	DiscoveryDepth int               // 環境ディレクトリを自動検出する階層の深さ
+	Groups         []*Group          // 環境グループ（グループ内の環境の一致と、グループ間の差分を分けて確認する）
+	Jobs           int               // 環境を並行して解析する数
+	NoCache        bool              // .tfspec/cache/ の解析結果のキャッシュを使用しない
+
+	FailOn        *FailOn // エラー終了させる検出結果の条件
+	BaseRef       string  // 指定時は基準ref時点からの差分の変化のみを報告する
+	BaselineFile  string  // 既知の構成ドリフトとして許容するベースラインファイル
+	WriteBaseline string  // 現在の構成ドリフトを書き込むベースラインファイル
+
+	OutputFile     string
+	OutputFlag     bool
+	MaxValueLength int
+	TrimCell       bool
+}
+
+// CheckOptions はcheckコマンドのオプション
+type CheckOptions struct {
+	EnvDirs        []string
+	Verbose        bool
+	OutputFile     string
+	OutputFlag     bool
+	NoFail         bool
+	ExcludeDirs    []string
+	MaxValueLength int
+	TrimCell       bool
+	StateFiles     map[string]string // 環境名 -> terraform show -json で出力したstateファイル
+	PlanFiles      map[string]string // 環境名 -> terraform show -json plan.out で出力したplanファイル
+
+	FailOn         []string // エラー終了させる検出結果（drift, existence-only, severity>=high等）
+	BaseRef        string   // 比較の基準とするgit ref（ブランチ名・タグ・コミットハッシュ）
+	BaselineFile   string
+	WriteBaseline  string
+	BaseEnv        string          // 他の環境の比較元とする環境
+	DiscoveryDepth int             // 環境ディレクトリを自動検出する階層の深さ
+	Groups         []*Group        // 環境グループ
+	Jobs           int             // 環境を並行して解析する数
+	NoCache        bool            // 解析結果のキャッシュを使用しない
+	Watch          bool            // 環境ディレクトリと.tfspec/の変更を監視し、変更のたびにチェックを再実行する
+	Stacks         bool            // .tfspecディレクトリを持つディレクトリをスタックとして検出し、スタックごとにチェックする
+	BaseDir        string          // 環境の検出・相対パスの基準ディレクトリ（空の場合は現在のディレクトリ）
+	SetFlags       map[string]bool // コマンドラインで指定されたフラグ（設定ファイルより優先する）
+}
+
+// Group は環境グループ（例: prod = [prod-tokyo, prod-osaka]）
+type Group struct {
+	Name string
+	Envs []string // 所属する環境名（1つ目をグループ内の比較元・グループの代表とする）
+}
+
+// HistoryOptions はhistoryコマンドのオプション
+type HistoryOptions struct {
+	EnvDir         string
+	Since          string // 比較の基準とするgit ref
+	OutputFile     string
+	OutputFlag     bool
+	MaxValueLength int
+	TrimCell       bool
 }
 
 // ConfigService は設定関連の処理を担当する
@@ -23,35 +90,144 @@ func NewConfigService() *ConfigService {
 }
 
 // LoadConfig は設定を読み込んで検証する
-func (s *ConfigService) LoadConfig(envDirs []string, verbose, noFail bool, excludeDirs []string) (*Config, error) {
-	tfspecDir, err := s.setupTfspecDir()
+func (s *ConfigService) LoadConfig(options *CheckOptions) (*Config, error) {
+	baseDir := options.BaseDir
+	if baseDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("現在のディレクトリを取得できませんでした: %w", err)
+		}
+		baseDir = cwd
+	}
+
+	tfspecDir, err := s.setupTfspecDir(baseDir)
 	if err != nil {
 		return nil, err
 	}
 
-	resolvedEnvDirs, err := s.resolveEnvDirs(envDirs, excludeDirs)
+	// 設定ファイルの値を反映（コマンドラインで指定された値が優先）
+	fileConfig, configFile, err := loadFileConfig(tfspecDir)
 	if err != nil {
 		return nil, err
 	}
+	if fileConfig != nil {
+		merged := *options
+		fileConfig.applyTo(&merged)
+		options = &merged
+	}
 
-	return &Config{
+	// スタックのチェックでは相対パスをスタックのディレクトリからのパスとして扱う
+	if options.BaseDir != "" {
+		merged := *options
+		merged.EnvDirs = resolvePaths(options.BaseDir, options.EnvDirs)
+		merged.BaselineFile = resolvePaths(options.BaseDir, []string{options.BaselineFile})[0]
+		merged.WriteBaseline = resolvePaths(options.BaseDir, []string{options.WriteBaseline})[0]
+		options = &merged
+	}
+
+	failOn, err := ParseFailOn(options.FailOn)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{
+		BaseDir:     baseDir,
 		TfspecDir:   tfspecDir,
-		EnvDirs:     resolvedEnvDirs,
-		Verbose:     verbose,
-		NoFail:      noFail,
-		ExcludeDirs: excludeDirs,
-	}, nil
-}
+		ConfigFile:  configFile,
+		Verbose:     options.Verbose,
+		NoFail:      options.NoFail,
+		ExcludeDirs: options.ExcludeDirs,
+		StateFiles:  options.StateFiles,
+		PlanFiles:   options.PlanFiles,
 
-// setupTfspecDir は.tfspecディレクトリの存在を確認し、パスを返す
-// ディレクトリが存在しない場合は空文字を返す（ignoreルールなしで動作）
-func (s *ConfigService) setupTfspecDir() (string, error) {
-	cwd, err := os.Getwd()
+		BaseEnv:        options.BaseEnv,
+		DiscoveryDepth: options.DiscoveryDepth,
+		Groups:         options.Groups,
+		Jobs:           options.Jobs,
+		NoCache:        options.NoCache,
+
+		FailOn:        failOn,
+		BaseRef:       options.BaseRef,
+		BaselineFile:  options.BaselineFile,
+		WriteBaseline: options.WriteBaseline,
+
+		OutputFile:     options.OutputFile,
+		OutputFlag:     options.OutputFlag,
+		MaxValueLength: options.MaxValueLength,
+		TrimCell:       options.TrimCell,
+	}
+
+	if options.BaseRef != "" && (len(options.StateFiles) > 0 || len(options.PlanFiles) > 0) {
+		return nil, fmt.Errorf("--base-ref は --state / --plan と同時に指定できません")
+	}
+	if options.BaseRef != "" && len(options.Groups) > 0 {
+		return nil, fmt.Errorf("--base-ref は環境グループと同時に指定できません")
+	}
+	if err := validateGroups(options.Groups); err != nil {
+		return nil, err
+	}
+	if options.Jobs < 1 {
+		return nil, fmt.Errorf("--jobs（設定ファイルの jobs）には1以上の値を指定してください: %d", options.Jobs)
+	}
+
+	// state / planファイルを比較する場合は環境ディレクトリを使用しない
+	if len(options.StateFiles) > 0 && len(options.PlanFiles) > 0 {
+		return nil, fmt.Errorf("--state と --plan は同時に指定できません")
+	}
+	if len(options.StateFiles) > 0 {
+		if err := s.validateJSONFiles("state", options.StateFiles, "terraform show -json > state.json"); err != nil {
+			return nil, err
+		}
+		return config, nil
+	}
+	if len(options.PlanFiles) > 0 {
+		if err := s.validateJSONFiles("plan", options.PlanFiles, "terraform show -json plan.out > plan.json"); err != nil {
+			return nil, err
+		}
+		return config, nil
+	}
+
+	config.EnvDirs, err = s.resolveEnvDirs(baseDir, options.EnvDirs, options.ExcludeDirs, options.DiscoveryDepth)
 	if err != nil {
-		return "", fmt.Errorf("現在のディレクトリを取得できませんでした: %w", err)
+		return nil, err
+	}
+	config.EnvNames = EnvNames(config.EnvDirs)
+
+	return config, nil
+}
+
+// validateJSONFiles はstate / planファイルの存在を確認する
+func (s *ConfigService) validateJSONFiles(kind string, files map[string]string, hint string) error {
+	var envNames []string
+	for envName, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("%sファイルが見つかりません:\n  環境: %s\n  ファイル: %s\n"+
+				"ヒント: %s で出力したファイルを指定してください", kind, envName, file, hint)
+		}
+		envNames = append(envNames, envName)
 	}
 
-	tfspecDir := filepath.Join(cwd, ".tfspec")
+	sort.Strings(envNames)
+	fmt.Printf("対象環境（%s）: %v\n", kind, envNames)
+	return nil
+}
+
+// resolvePaths は相対パスを基準ディレクトリからのパスに変換する（空文字・絶対パスはそのまま）
+func resolvePaths(baseDir string, paths []string) []string {
+	resolved := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		resolved = append(resolved, path)
+	}
+	return resolved
+}
+
+// setupTfspecDir は基準ディレクトリ内の.tfspecディレクトリの存在を確認し、パスを返す
+// ディレクトリが存在しない場合は空文字を返す（ignoreルールなしで動作）
+func (s *ConfigService) setupTfspecDir(baseDir string) (string, error) {
+	tfspecDir := filepath.Join(baseDir, ".tfspec")
 	if _, err := os.Stat(tfspecDir); os.IsNotExist(err) {
 		// .tfspecディレクトリが存在しない場合は空文字を返す（ignoreルールなし）
 		return "", nil
@@ -61,16 +237,23 @@ func (s *ConfigService) setupTfspecDir() (string, error) {
 }
 
 // resolveEnvDirs は環境ディレクトリを解決する
-func (s *ConfigService) resolveEnvDirs(envDirs []string, excludeDirs []string) ([]string, error) {
+// 指定がない場合は基準ディレクトリから discoveryDepth 階層まで自動検出し、globパターン（envs/*/*）は展開する
+func (s *ConfigService) resolveEnvDirs(baseDir string, envDirs []string, excludeDirs []string, discoveryDepth int) ([]string, error) {
+	if discoveryDepth < 1 {
+		return nil, fmt.Errorf("--discovery-depth には1以上を指定してください: %d", discoveryDepth)
+	}
+
 	if len(envDirs) == 0 {
-		cwd, err := os.Getwd()
+		var err error
+		envDirs, err = s.detectEnvDirs(baseDir, excludeDirs, discoveryDepth)
 		if err != nil {
-			return nil, fmt.Errorf("現在のディレクトリを取得できませんでした: %w", err)
+			return nil, fmt.Errorf("環境ディレクトリの自動検出に失敗しました: %w", err)
 		}
-
-		envDirs, err = s.detectEnvDirs(cwd, excludeDirs)
+	} else {
+		var err error
+		envDirs, err = s.expandEnvDirPatterns(envDirs, excludeDirs)
 		if err != nil {
-			return nil, fmt.Errorf("環境ディレクトリの自動検出に失敗しました: %w", err)
+			return nil, err
 		}
 	}
 
@@ -86,8 +269,50 @@ func (s *ConfigService) resolveEnvDirs(envDirs []string, excludeDirs []string) (
 	return envDirs, nil
 }
 
+// expandEnvDirPatterns はglobパターンを含む環境ディレクトリの指定を展開する（.tf/.hclファイルを含むディレクトリのみ）
+func (s *ConfigService) expandEnvDirPatterns(patterns []string, excludeDirs []string) ([]string, error) {
+	var envDirs []string
+	seen := make(map[string]bool)
+
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			if !seen[pattern] {
+				seen[pattern] = true
+				envDirs = append(envDirs, pattern)
+			}
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("環境ディレクトリのパターンが正しくありません: %s\n  エラー: %w", pattern, err)
+		}
+
+		var matched int
+		for _, match := range matches {
+			if seen[match] || s.isExcluded(filepath.Base(match), excludeDirs) {
+				continue
+			}
+			if hasTerraformFiles, err := s.hasTerraformFiles(match); err != nil || !hasTerraformFiles {
+				continue // ファイル・Terraformファイルを含まないディレクトリは対象外
+			}
+			seen[match] = true
+			envDirs = append(envDirs, match)
+			matched++
+		}
+
+		if matched == 0 {
+			return nil, fmt.Errorf("パターンに一致する環境ディレクトリがありません: %s\n"+
+				"ヒント: .tf または .hcl ファイルを含むディレクトリに一致するパターンを指定してください", pattern)
+		}
+	}
+
+	return envDirs, nil
+}
+
 // detectEnvDirs は環境ディレクトリを自動検出する
-func (s *ConfigService) detectEnvDirs(baseDir string, excludeDirs []string) ([]string, error) {
+// .tf/.hclファイルを含まないディレクトリは depth 階層まで配下を探索する（envs/<region>/<env>/ 等の構成用）
+func (s *ConfigService) detectEnvDirs(baseDir string, excludeDirs []string, depth int) ([]string, error) {
 	var envDirs []string
 
 	entries, err := os.ReadDir(baseDir)
@@ -96,7 +321,8 @@ func (s *ConfigService) detectEnvDirs(baseDir string, excludeDirs []string) ([]s
 	}
 
 	for _, entry := range entries {
-		if !entry.IsDir() || entry.Name() == ".tfspec" {
+		// .tfspec・.git・.terraform等の隠しディレクトリは対象外
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 
@@ -112,13 +338,57 @@ func (s *ConfigService) detectEnvDirs(baseDir string, excludeDirs []string) ([]s
 		}
 
 		if hasTerraformFiles {
+			// 環境ディレクトリの配下（ローカルモジュール等）は探索しない
 			envDirs = append(envDirs, envPath)
+			continue
+		}
+
+		if depth > 1 {
+			childEnvDirs, err := s.detectEnvDirs(envPath, excludeDirs, depth-1)
+			if err != nil {
+				continue
+			}
+			envDirs = append(envDirs, childEnvDirs...)
 		}
 	}
 
 	return envDirs, nil
 }
 
+// EnvNames は環境ディレクトリごとの環境名を返す
+// 全ての環境ディレクトリに共通する親ディレクトリを除いたパスを環境名とする（例: envs/tokyo/prod -> tokyo/prod）
+func EnvNames(envDirs []string) map[string]string {
+	segments := make([][]string, len(envDirs))
+	for i, envDir := range envDirs {
+		absDir, err := filepath.Abs(envDir)
+		if err != nil {
+			absDir = envDir
+		}
+		segments[i] = strings.Split(filepath.ToSlash(filepath.Clean(absDir)), "/")
+	}
+
+	// 共通する親ディレクトリの階層数（少なくとも最後の階層は環境名に残す）
+	var common int
+	if len(segments) > 0 {
+		common = len(segments[0]) - 1
+	}
+	for _, segment := range segments[1:] {
+		common = min(common, len(segment)-1)
+		for i := 0; i < common; i++ {
+			if segment[i] != segments[0][i] {
+				common = i
+				break
+			}
+		}
+	}
+
+	envNames := make(map[string]string, len(envDirs))
+	for i, envDir := range envDirs {
+		envNames[envDir] = strings.Join(segments[i][common:], "/")
+	}
+	return envNames
+}
+
 // hasTerraformFiles は指定ディレクトリに .tf または .hcl ファイルが存在するかチェックする
 func (s *ConfigService) hasTerraformFiles(dir string) (bool, error) {
 	entries, err := os.ReadDir(dir)
@@ -146,4 +416,27 @@ func (s *ConfigService) isExcluded(dirName string, excludeDirs []string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// validateGroups は環境グループの定義を検証する
+func validateGroups(groups []*Group) error {
+	groupNames := make(map[string]bool)
+	memberOf := make(map[string]string)
+	for _, group := range groups {
+		if groupNames[group.Name] {
+			return fmt.Errorf("環境グループ '%s' が複数回定義されています", group.Name)
+		}
+		groupNames[group.Name] = true
+
+		if len(group.Envs) == 0 {
+			return fmt.Errorf("環境グループ '%s' に環境が指定されていません", group.Name)
+		}
+		for _, envName := range group.Envs {
+			if other, exists := memberOf[envName]; exists {
+				return fmt.Errorf("環境 '%s' が複数のグループ（%s, %s）に含まれています", envName, other, group.Name)
+			}
+			memberOf[envName] = group.Name
+		}
+	}
+	return nil
+}
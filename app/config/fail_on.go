@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Mkamono/tfspec/app/types"
+)
+
+// FailOn はcheckコマンドをエラー終了させる検出結果の条件
+type FailOn struct {
+	Drift          bool   // 全ての構成ドリフト
+	ExistenceOnly  bool   // リソース等の存在に関する構成ドリフトのみ
+	MinSeverity    string // 指定した重要度以上の構成ドリフトのみ（空の場合は対象外）
+	StaleRules     bool   // 実際のリソース構成に存在しない無視ルール
+	ExpiredRules   bool   // 期限切れの無視ルール
+	PendingChanges bool   // planに含まれる未適用の変更
+}
+
+// ParseFailOn は --fail-on の値（drift, existence-only, severity>=high, stale-rules, expired-rules, pending-changes）を解析する
+func ParseFailOn(values []string) (*FailOn, error) {
+	failOn := &FailOn{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		switch value {
+		case "drift":
+			failOn.Drift = true
+		case "existence-only":
+			failOn.ExistenceOnly = true
+		case "stale-rules":
+			failOn.StaleRules = true
+		case "expired-rules":
+			failOn.ExpiredRules = true
+		case "pending-changes":
+			failOn.PendingChanges = true
+		default:
+			severity, found := strings.CutPrefix(value, "severity>=")
+			if !found || types.SeverityRank(severity) == 0 {
+				return nil, fmt.Errorf("--fail-on に不明な値が指定されています: %s\n"+
+					"ヒント: drift, existence-only, severity>=<info|low|medium|high|critical>, stale-rules, expired-rules, pending-changes のいずれかを指定してください", value)
+			}
+			failOn.MinSeverity = severity
+		}
+	}
+	return failOn, nil
+}
+
+// Values は条件を --fail-on の値の形式で返す
+func (f *FailOn) Values() []string {
+	var values []string
+	if f.Drift {
+		values = append(values, "drift")
+	}
+	if f.ExistenceOnly {
+		values = append(values, "existence-only")
+	}
+	if f.MinSeverity != "" {
+		values = append(values, "severity>="+f.MinSeverity)
+	}
+	if f.StaleRules {
+		values = append(values, "stale-rules")
+	}
+	if f.ExpiredRules {
+		values = append(values, "expired-rules")
+	}
+	if f.PendingChanges {
+		values = append(values, "pending-changes")
+	}
+	return values
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// configFileName は.tfspecディレクトリ内の設定ファイル名
+const configFileName = "config.hcl"
+
+// FileConfig は設定ファイル（.tfspec/config.hcl）の内容
+type FileConfig struct {
+	Stacks         []string       `hcl:"stacks,optional"` // スタックのディレクトリ（ルートの設定ファイルのみ）
+	EnvDirs        []string       `hcl:"env_dirs,optional"`
+	ExcludeDirs    []string       `hcl:"exclude_dirs,optional"`
+	DiscoveryDepth *int           `hcl:"discovery_depth,optional"`
+	BaseEnv        *string        `hcl:"base_env,optional"`
+	FailOn         []string       `hcl:"fail_on,optional"`
+	NoFail         *bool          `hcl:"no_fail,optional"`
+	BaselineFile   *string        `hcl:"baseline_file,optional"`
+	Jobs           *int           `hcl:"jobs,optional"`
+	NoCache        *bool          `hcl:"no_cache,optional"`
+	Groups         []*GroupConfig `hcl:"group,block"`
+	Output         *OutputConfig  `hcl:"output,block"`
+}
+
+// GroupConfig は設定ファイルのgroupブロック（group "prod" { envs = [...] }）
+type GroupConfig struct {
+	Name string   `hcl:"name,label"`
+	Envs []string `hcl:"envs"`
+}
+
+// OutputConfig は設定ファイルのoutputブロック
+type OutputConfig struct {
+	File           *string `hcl:"file,optional"` // 指定時は常にレポートをファイルに出力する
+	MaxValueLength *int    `hcl:"max_value_length,optional"`
+	TrimCell       *bool   `hcl:"trim_cell,optional"`
+	Verbose        *bool   `hcl:"verbose,optional"`
+}
+
+// loadFileConfig は設定ファイルを読み込む（ファイルがない場合はnil）
+func loadFileConfig(tfspecDir string) (*FileConfig, string, error) {
+	if tfspecDir == "" {
+		return nil, "", nil
+	}
+
+	filename := filepath.Join(tfspecDir, configFileName)
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil, "", nil
+	}
+
+	file, diags := hclparse.NewParser().ParseHCLFile(filename)
+	if diags.HasErrors() {
+		return nil, "", fmt.Errorf("設定ファイルの解析に失敗しました:\n  ファイル: %s\n  エラー: %s", filename, diags.Error())
+	}
+
+	var fileConfig FileConfig
+	if diags := gohcl.DecodeBody(file.Body, nil, &fileConfig); diags.HasErrors() {
+		return nil, "", fmt.Errorf("設定ファイルの内容が正しくありません:\n  ファイル: %s\n  エラー: %s\n"+
+			"ヒント: 指定できる項目は stacks, env_dirs, exclude_dirs, discovery_depth, base_env, fail_on, no_fail, baseline_file, jobs, no_cache, group ブロック, output ブロックです", filename, diags.Error())
+	}
+
+	return &fileConfig, filename, nil
+}
+
+// applyTo は設定ファイルの値をオプションに反映する（コマンドラインで指定された値が優先）
+func (f *FileConfig) applyTo(options *CheckOptions) {
+	if len(options.EnvDirs) == 0 {
+		options.EnvDirs = f.EnvDirs
+	}
+	if f.ExcludeDirs != nil && !options.SetFlags["exclude-dirs"] {
+		options.ExcludeDirs = f.ExcludeDirs
+	}
+	if f.DiscoveryDepth != nil && !options.SetFlags["discovery-depth"] {
+		options.DiscoveryDepth = *f.DiscoveryDepth
+	}
+	if f.BaseEnv != nil && !options.SetFlags["base-env"] {
+		options.BaseEnv = *f.BaseEnv
+	}
+	if f.FailOn != nil && !options.SetFlags["fail-on"] {
+		options.FailOn = f.FailOn
+	}
+	if f.NoFail != nil && !options.SetFlags["no-fail"] {
+		options.NoFail = *f.NoFail
+	}
+	if f.BaselineFile != nil && !options.SetFlags["baseline-file"] {
+		options.BaselineFile = *f.BaselineFile
+	}
+	if f.Jobs != nil && !options.SetFlags["jobs"] {
+		options.Jobs = *f.Jobs
+	}
+	if f.NoCache != nil && !options.SetFlags["no-cache"] {
+		options.NoCache = *f.NoCache
+	}
+	if f.Groups != nil && !options.SetFlags["group"] {
+		options.Groups = nil
+		for _, group := range f.Groups {
+			options.Groups = append(options.Groups, &Group{Name: group.Name, Envs: group.Envs})
+		}
+	}
+
+	if f.Output == nil {
+		return
+	}
+	if f.Output.File != nil && !options.SetFlags["output"] {
+		options.OutputFile = *f.Output.File
+		options.OutputFlag = true
+	}
+	if f.Output.MaxValueLength != nil && !options.SetFlags["max-value-length"] {
+		options.MaxValueLength = *f.Output.MaxValueLength
+	}
+	if f.Output.TrimCell != nil && !options.SetFlags["trim-cell"] {
+		options.TrimCell = *f.Output.TrimCell
+	}
+	if f.Output.Verbose != nil && !options.SetFlags["verbose"] {
+		options.Verbose = *f.Output.Verbose
+	}
+}
+
+// FormatConfig は有効な設定を設定ファイルと同じHCL形式で返す
+func FormatConfig(config *Config) string {
+	file := hclwrite.NewEmptyFile()
+	body := file.Body()
+
+	if config.ConfigFile != "" {
+		body.AppendUnstructuredTokens(commentTokens("# 設定ファイル: " + config.ConfigFile))
+	} else {
+		body.AppendUnstructuredTokens(commentTokens("# 設定ファイル: なし"))
+	}
+	body.SetAttributeValue("env_dirs", stringList(config.EnvDirs))
+	body.SetAttributeValue("exclude_dirs", stringList(config.ExcludeDirs))
+	body.SetAttributeValue("discovery_depth", cty.NumberIntVal(int64(config.DiscoveryDepth)))
+	body.SetAttributeValue("base_env", cty.StringVal(config.BaseEnv))
+	body.SetAttributeValue("fail_on", stringList(config.FailOn.Values()))
+	body.SetAttributeValue("no_fail", cty.BoolVal(config.NoFail))
+	body.SetAttributeValue("baseline_file", cty.StringVal(config.BaselineFile))
+	body.SetAttributeValue("jobs", cty.NumberIntVal(int64(config.Jobs)))
+	body.SetAttributeValue("no_cache", cty.BoolVal(config.NoCache))
+
+	for _, group := range config.Groups {
+		body.AppendNewline()
+		groupBody := body.AppendNewBlock("group", []string{group.Name}).Body()
+		groupBody.SetAttributeValue("envs", stringList(group.Envs))
+	}
+
+	body.AppendNewline()
+	output := body.AppendNewBlock("output", nil).Body()
+	if config.OutputFlag {
+		output.SetAttributeValue("file", cty.StringVal(config.OutputFile))
+	}
+	output.SetAttributeValue("max_value_length", cty.NumberIntVal(int64(config.MaxValueLength)))
+	output.SetAttributeValue("trim_cell", cty.BoolVal(config.TrimCell))
+	output.SetAttributeValue("verbose", cty.BoolVal(config.Verbose))
+
+	return string(file.Bytes())
+}
+
+// commentTokens はコメント行のトークンを返す
+func commentTokens(comment string) hclwrite.Tokens {
+	return hclwrite.Tokens{
+		{Type: hclsyntax.TokenComment, Bytes: []byte(comment + "\n")},
+	}
+}
+
+// stringList は文字列のスライスをHCLのリストに変換する
+func stringList(values []string) cty.Value {
+	if len(values) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	list := make([]cty.Value, 0, len(values))
+	for _, value := range values {
+		list = append(list, cty.StringVal(value))
+	}
+	return cty.ListVal(list)
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Stack はそれぞれの環境と.tfspecディレクトリを持つスタック
+type Stack struct {
+	Name string // 現在のディレクトリからの相対パス（例: stacks/network）
+	Dir  string
+}
+
+// StackConfig はスタック構成（1回の実行で複数のスタックをチェックする）
+type StackConfig struct {
+	Stacks  []*Stack
+	Options *CheckOptions // ルートの設定ファイルを反映したオプション（統合レポートの出力に使用する）
+}
+
+// LoadStacks はスタック構成を読み込む（--stacks 指定時、またはルートの設定ファイルでstacksを宣言した場合）
+// スタック構成でない場合はnilを返す
+func (s *ConfigService) LoadStacks(options *CheckOptions) (*StackConfig, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("現在のディレクトリを取得できませんでした: %w", err)
+	}
+
+	tfspecDir, err := s.setupTfspecDir(cwd)
+	if err != nil {
+		return nil, err
+	}
+	fileConfig, _, err := loadFileConfig(tfspecDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var declaredStacks []string
+	if fileConfig != nil {
+		declaredStacks = fileConfig.Stacks
+	}
+	if !options.Stacks && len(declaredStacks) == 0 {
+		return nil, nil
+	}
+
+	if len(options.EnvDirs) > 0 {
+		return nil, fmt.Errorf("スタック構成では環境ディレクトリを引数で指定できません\n" +
+			"ヒント: 各スタックの環境は .tfspec/config.hcl の env_dirs で指定してください")
+	}
+	if len(options.StateFiles) > 0 || len(options.PlanFiles) > 0 {
+		return nil, fmt.Errorf("スタック構成では --state / --plan を指定できません")
+	}
+
+	rootOptions := *options
+	if fileConfig != nil {
+		fileConfig.applyTo(&rootOptions)
+	}
+
+	var stackDirs []string
+	if len(declaredStacks) > 0 {
+		for _, stack := range declaredStacks {
+			stackDir := filepath.Join(cwd, stack)
+			if info, err := os.Stat(stackDir); err != nil || !info.IsDir() {
+				return nil, fmt.Errorf("スタックのディレクトリが見つかりません: %s\n"+
+					"ヒント: .tfspec/config.hcl の stacks を確認してください", stack)
+			}
+			stackDirs = append(stackDirs, stackDir)
+		}
+	} else {
+		stackDirs, err = s.discoverStacks(cwd, rootOptions.ExcludeDirs)
+		if err != nil {
+			return nil, fmt.Errorf("スタックの自動検出に失敗しました: %w", err)
+		}
+	}
+
+	if len(stackDirs) == 0 {
+		return nil, fmt.Errorf("スタックが見つかりませんでした\n" +
+			"ヒント: 各スタックのディレクトリに .tfspec/ ディレクトリを作成するか、.tfspec/config.hcl の stacks で指定してください")
+	}
+
+	stacks := make([]*Stack, 0, len(stackDirs))
+	for _, stackDir := range stackDirs {
+		name, err := filepath.Rel(cwd, stackDir)
+		if err != nil {
+			name = stackDir
+		}
+		stacks = append(stacks, &Stack{Name: filepath.ToSlash(name), Dir: stackDir})
+	}
+
+	return &StackConfig{Stacks: stacks, Options: &rootOptions}, nil
+}
+
+// discoverStacks は.tfspecディレクトリを持つディレクトリをスタックとして検出する（スタックの配下は探索しない）
+func (s *ConfigService) discoverStacks(rootDir string, excludeDirs []string) ([]string, error) {
+	var stackDirs []string
+
+	err := filepath.WalkDir(rootDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() || path == rootDir {
+			return nil // 読み込めないディレクトリは無視して次へ
+		}
+		if strings.HasPrefix(entry.Name(), ".") || s.isExcluded(entry.Name(), excludeDirs) {
+			return filepath.SkipDir
+		}
+
+		if info, err := os.Stat(filepath.Join(path, ".tfspec")); err == nil && info.IsDir() {
+			stackDirs = append(stackDirs, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stackDirs, nil
+}
+
+// ForStack はスタックをチェックするためのオプションを返す（スタックの設定ファイルより、コマンドラインで指定した値が優先）
+func (o *CheckOptions) ForStack(stack *Stack) *CheckOptions {
+	stackOptions := *o
+	stackOptions.BaseDir = stack.Dir
+	stackOptions.EnvDirs = nil // 環境はスタックごとに検出・指定する
+	stackOptions.Stacks = false
+	stackOptions.OutputFlag = false // レポートは統合して出力する
+	return &stackOptions
+}
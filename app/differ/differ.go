@@ -11,6 +11,7 @@ import (
 
 type HCLDiffer struct {
 	ignoreMatcher *IgnoreMatcher
+	baseEnv       string // 比較元とする環境（空の場合は環境名の順で最初の環境）
 }
 
 func NewHCLDiffer(ignoreRules []string) *HCLDiffer {
@@ -62,9 +63,9 @@ func (d *HCLDiffer) compareMapAttributes(baseMap, targetMap map[string]cty.Value
 
 // checkExistenceDiff は名前付きアイテムの存在差分をチェックする汎用ヘルパー関数
 // baseMap, targetMap: 比較するマップ
-// resourcePrefix: リソースパスのプレフィックス（例："module", "local", "var"）
+// kind: ブロックの種類（types.KindModule, types.KindLocal, types.KindVariable等）
 // env: 環境名
-func (d *HCLDiffer) checkExistenceDiff(baseMap, targetMap map[string]bool, resourcePrefix, env string) []*types.DiffResult {
+func (d *HCLDiffer) checkExistenceDiff(baseMap, targetMap map[string]bool, kind, env string) []*types.DiffResult {
 	var results []*types.DiffResult
 
 	// 全名を収集
@@ -82,16 +83,7 @@ func (d *HCLDiffer) checkExistenceDiff(baseMap, targetMap map[string]bool, resou
 		targetExists := targetMap[name]
 
 		if baseExists != targetExists {
-			resourcePath := fmt.Sprintf("%s.%s", resourcePrefix, name)
-			diff := &types.DiffResult{
-				Resource:    resourcePath,
-				Environment: env,
-				Path:        "",
-				Expected:    cty.BoolVal(baseExists),
-				Actual:      cty.BoolVal(targetExists),
-				IsIgnored:   d.ignoreMatcher.IsIgnored(resourcePath),
-			}
-			results = append(results, diff)
+			results = append(results, d.newDiff(types.BlockAddress(kind, name), env, cty.BoolVal(baseExists), cty.BoolVal(targetExists)))
 		}
 	}
 
@@ -103,78 +95,99 @@ func (d *HCLDiffer) Compare(envResources map[string]*types.EnvResources) ([]*typ
 	var results []*types.DiffResult
 
 	// .tfspecignoreルールの検証を実行
-	envResourcesMap := make(map[string]map[string]*types.EnvResource)
-	for envName, envRes := range envResources {
-		envResourcesMap[envName] = make(map[string]*types.EnvResource)
-		for _, resource := range envRes.Resources {
-			key := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
-			envResourcesMap[envName][key] = resource
-		}
-	}
-	d.ignoreMatcher.ValidateRules(envResourcesMap)
+	d.ignoreMatcher.ValidateRules(envResources)
 
-	// 環境名のスライスを作成（決定的な順序でソート）
+	// 環境名のスライスを作成（決定的な順序でソートし、比較元の環境を先頭にする）
 	var envNames []string
 	for envName := range envResources {
 		envNames = append(envNames, envName)
 	}
-	sort.Strings(envNames)
+	envNames, err := OrderEnvNames(envNames, d.baseEnv)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(envNames) < 2 {
 		return results, nil // 比較対象が1つ以下の場合は差分なし
 	}
 
-	// 基準環境を1つ目とし、他の環境と比較
+	// 比較元の環境を1つ目とし、他の環境と比較
 	baseEnv := envNames[0]
 	baseEnvResources := envResources[baseEnv]
 
 	for i := 1; i < len(envNames); i++ {
 		env := envNames[i]
-		envResourceList := envResources[env]
-
-		// リソース存在差分を検出
-		existenceDiffs := d.compareResourceExistence(baseEnvResources, envResourceList, env)
-		results = append(results, existenceDiffs...)
-
-		// 共通リソースの属性・ブロック差分を検出
-		for _, baseResource := range baseEnvResources.Resources {
-			for _, resource := range envResourceList.Resources {
-				// リソース種別・名前が同じかチェック
-				if baseResource.Type == resource.Type && baseResource.Name == resource.Name {
-					// 属性を比較
-					envDiffs := d.compareAttributes(baseResource, resource, env)
-					results = append(results, envDiffs...)
-
-					// ネストブロックを比較
-					blockDiffs := d.compareBlocks(baseResource, resource, env)
-					results = append(results, blockDiffs...)
-				}
-			}
-		}
+		envDiffs := d.compareEnvResources(baseEnvResources, envResources[env], env)
+		results = append(results, envDiffs...)
+	}
 
-		// 新しいブロックタイプの比較
-		// Modules
-		moduleDiffs := d.compareModules(baseEnvResources.Modules, envResourceList.Modules, env)
-		results = append(results, moduleDiffs...)
+	// 構成に存在するが差分のない無視ルールを警告
+	d.ignoreMatcher.CheckUnmatchedRules()
 
-		// Locals
-		localDiffs := d.compareLocals(baseEnvResources.Locals, envResourceList.Locals, env)
-		results = append(results, localDiffs...)
+	return results, nil
+}
 
-		// Variables
-		variableDiffs := d.compareVariables(baseEnvResources.Variables, envResourceList.Variables, env)
-		results = append(results, variableDiffs...)
+// compareEnvResources は基準環境と比較環境の全ブロックの差分を検出する
+func (d *HCLDiffer) compareEnvResources(baseEnvResources, envResourceList *types.EnvResources, env string) []*types.DiffResult {
+	var results []*types.DiffResult
 
-		// Outputs
-		outputDiffs := d.compareOutputs(baseEnvResources.Outputs, envResourceList.Outputs, env)
-		results = append(results, outputDiffs...)
+	// リソース存在差分を検出
+	existenceDiffs := d.compareResourceExistence(baseEnvResources, envResourceList, env)
+	results = append(results, existenceDiffs...)
 
-		// Data Sources
-		dataDiffs := d.compareDataSources(baseEnvResources.DataSources, envResourceList.DataSources, env)
-		results = append(results, dataDiffs...)
+	// 共通リソースの属性・ブロック差分を検出（リソースアドレスの索引で対応付ける）
+	envIndex := envResourceList.Index()
+	for _, baseResource := range baseEnvResources.Resources {
+		address := baseResource.Address()
+		for _, resource := range envIndex.Resources(address) {
+			// 属性を比較
+			envDiffs := d.compareAttributes(address, baseResource, resource, env)
+			results = append(results, envDiffs...)
+
+			// ネストブロックを比較
+			blockDiffs := d.compareBlocks(address, baseResource, resource, env)
+			results = append(results, blockDiffs...)
+		}
 	}
 
-	return results, nil
+	// 新しいブロックタイプの比較
+	// Modules
+	moduleDiffs := d.compareModules(baseEnvResources.Modules, envResourceList.Modules, env)
+	results = append(results, moduleDiffs...)
+
+	// Locals
+	localDiffs := d.compareLocals(baseEnvResources.Locals, envResourceList.Locals, env)
+	results = append(results, localDiffs...)
+
+	// Variables
+	variableDiffs := d.compareVariables(baseEnvResources.Variables, envResourceList.Variables, env)
+	results = append(results, variableDiffs...)
+
+	// Outputs
+	outputDiffs := d.compareOutputs(baseEnvResources.Outputs, envResourceList.Outputs, env)
+	results = append(results, outputDiffs...)
+
+	// Data Sources
+	dataDiffs := d.compareDataSources(baseEnvResources.DataSources, envResourceList.DataSources, env)
+	results = append(results, dataDiffs...)
+
+	// Providers
+	providerDiffs := d.compareProviders(baseEnvResources.Providers, envResourceList.Providers, env)
+	results = append(results, providerDiffs...)
+
+	// Terraform settings (required_version, required_providers, backend)
+	terraformDiffs := d.compareTerraform(baseEnvResources.Terraform, envResourceList.Terraform, env)
+	results = append(results, terraformDiffs...)
+
+	// terragrunt.hcl (inputs, terraform.source, remote_state)
+	terragruntDiffs := d.compareTerragrunt(baseEnvResources.Terragrunt, envResourceList.Terragrunt, env)
+	results = append(results, terragruntDiffs...)
+
+	// moved / import / removed / check
+	metaBlockDiffs := d.compareMetaBlocks(baseEnvResources, envResourceList, env)
+	results = append(results, metaBlockDiffs...)
+
+	return results
 }
 
 // GetIgnoreWarnings は.tfspecignoreルール検証で発見された警告を返す
@@ -182,76 +195,114 @@ func (d *HCLDiffer) GetIgnoreWarnings() []string {
 	return d.ignoreMatcher.GetWarnings()
 }
 
-// リソース存在差分を検出
-func (d *HCLDiffer) compareResourceExistence(baseResources, envResources *types.EnvResources, env string) []*types.DiffResult {
-	var results []*types.DiffResult
+// GetStaleRules は実際のリソース構成に存在しない無視ルールを返す
+func (d *HCLDiffer) GetStaleRules() []string {
+	return d.ignoreMatcher.GetStaleRules()
+}
 
-	// 基準環境のリソースをマップ化
-	baseResourceMap := make(map[string]*types.EnvResource)
-	for _, resource := range baseResources.Resources {
-		key := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
-		baseResourceMap[key] = resource
+// SetBaseEnv は他の環境の比較元とする環境を設定する
+func (d *HCLDiffer) SetBaseEnv(baseEnv string) {
+	d.baseEnv = baseEnv
+}
+
+// OrderEnvNames は環境名をソートし、比較元の環境を先頭にする（baseEnvが空の場合はソートのみ）
+func OrderEnvNames(envNames []string, baseEnv string) ([]string, error) {
+	ordered := append([]string{}, envNames...)
+	sort.Strings(ordered)
+	if baseEnv == "" {
+		return ordered, nil
+	}
+
+	for i, envName := range ordered {
+		if envName == baseEnv {
+			return append([]string{baseEnv}, append(ordered[:i:i], ordered[i+1:]...)...), nil
+		}
 	}
+	return nil, fmt.Errorf("比較元の環境 '%s' が見つかりません\n"+
+		"ヒント: base_env には対象環境 %v のいずれかを指定してください", baseEnv, ordered)
+}
+
+// GetExpiredRules は期限切れの無視ルールを返す
+func (d *HCLDiffer) GetExpiredRules() []string {
+	return d.ignoreMatcher.GetExpiredRules()
+}
 
-	// 比較環境のリソースをマップ化
-	envResourceMap := make(map[string]*types.EnvResource)
-	for _, resource := range envResources.Resources {
-		key := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
-		envResourceMap[key] = resource
+// newDiff はアドレス（属性パスを含む）の差分結果を生成し、無視ルールで判定する
+func (d *HCLDiffer) newDiff(address types.Address, env string, expected, actual cty.Value) *types.DiffResult {
+	return &types.DiffResult{
+		Resource:    address.Resource(),
+		Environment: env,
+		Path:        address.Path,
+		Expected:    expected,
+		Actual:      actual,
+		IsIgnored:   d.isIgnored(address),
 	}
+}
+
+// isIgnored はアドレスが無視ルールにマッチするかチェックする
+// count / for_each で展開されたインスタンスは、インスタンスキー付き（aws_instance.web[0].x）と
+// インスタンスキーなし（aws_instance.web.x）のどちらのルールでも無視できる（providerのaliasも同様）
+func (d *HCLDiffer) isIgnored(address types.Address) bool {
+	if d.ignoreMatcher.IsIgnored(address) {
+		return true
+	}
+	return address.Key != "" && d.ignoreMatcher.IsIgnored(address.WithoutKey())
+}
+
+// dataAsResource はEnvDataをEnvResourceとして扱えるように変換する
+func dataAsResource(data *types.EnvData) *types.EnvResource {
+	return &types.EnvResource{
+		Type:   data.Type,
+		Name:   data.Name,
+		Key:    data.Key,
+		Attrs:  data.Attrs,
+		Blocks: data.Blocks,
+	}
+}
 
-	// 全リソースキーを収集
-	allResourceKeys := make(map[string]bool)
-	for key := range baseResourceMap {
-		allResourceKeys[key] = true
+// リソース存在差分を検出
+func (d *HCLDiffer) compareResourceExistence(baseResources, envResources *types.EnvResources, env string) []*types.DiffResult {
+	var results []*types.DiffResult
+
+	// 基準環境・比較環境のリソースの索引
+	baseIndex := baseResources.Index()
+	envIndex := envResources.Index()
+
+	// 全リソースのアドレスを収集
+	allAddresses := make(map[string]types.Address)
+	for _, address := range baseIndex.ResourceAddresses() {
+		allAddresses[address.String()] = address
 	}
-	for key := range envResourceMap {
-		allResourceKeys[key] = true
+	for _, address := range envIndex.ResourceAddresses() {
+		allAddresses[address.String()] = address
 	}
 
 	// 各リソースの存在を比較
-	for resourceKey := range allResourceKeys {
-		baseExists := baseResourceMap[resourceKey] != nil
-		envExists := envResourceMap[resourceKey] != nil
+	for _, address := range allAddresses {
+		baseExists := baseIndex.HasResource(address)
+		envExists := envIndex.HasResource(address)
 
 		if baseExists != envExists {
-			// リソース存在差分を記録
-			diff := &types.DiffResult{
-				Resource:    resourceKey,
-				Environment: env,
-				Path:        "",  // リソース全体の存在差分なのでパスは空
-				Expected:    cty.BoolVal(baseExists),
-				Actual:      cty.BoolVal(envExists),
-				IsIgnored:   d.ignoreMatcher.IsIgnored(resourceKey),
-			}
-			results = append(results, diff)
+			// リソース存在差分を記録（リソース全体の存在差分なのでパスは空）
+			results = append(results, d.newDiff(address, env, cty.BoolVal(baseExists), cty.BoolVal(envExists)))
 		}
 	}
 
 	return results
 }
 
-// 2つのリソースの属性を比較
-func (d *HCLDiffer) compareAttributes(baseResource, resource *types.EnvResource, env string) []*types.DiffResult {
+// 2つのリソースの属性を比較（addressは比較するブロックのアドレス）
+func (d *HCLDiffer) compareAttributes(address types.Address, baseResource, resource *types.EnvResource, env string) []*types.DiffResult {
 	callback := func(attrName string, baseValue, value cty.Value, baseExists, exists bool) *types.DiffResult {
 		// 値が異なる場合、差分として記録
 		if !baseValue.Equals(value).True() {
-			resourcePath := fmt.Sprintf("%s.%s.%s", baseResource.Type, baseResource.Name, attrName)
-
 			// tags属性の場合は、ネストした属性も個別にチェック
 			if attrName == "tags" && baseValue.Type().IsObjectType() && value.Type().IsObjectType() {
 				// このコールバック内では処理しない（親関数で処理）
 				return nil
 			}
 
-			return &types.DiffResult{
-				Resource:    fmt.Sprintf("%s.%s", baseResource.Type, baseResource.Name),
-				Environment: env,
-				Path:        attrName,
-				Expected:    baseValue,
-				Actual:      value,
-				IsIgnored:   d.ignoreMatcher.IsIgnored(resourcePath),
-			}
+			return d.newDiff(address.WithPath(attrName), env, baseValue, value)
 		}
 		return nil
 	}
@@ -262,7 +313,7 @@ func (d *HCLDiffer) compareAttributes(baseResource, resource *types.EnvResource,
 	baseTags, baseHasTags := baseResource.Attrs["tags"]
 	tags, hasTags := resource.Attrs["tags"]
 	if baseHasTags && hasTags && baseTags.Type().IsObjectType() && tags.Type().IsObjectType() {
-		nestedDiffs := d.compareTagAttributes(baseResource, baseTags, tags, env)
+		nestedDiffs := d.compareTagAttributes(address, baseTags, tags, env)
 		results = append(results, nestedDiffs...)
 	}
 
@@ -270,7 +321,7 @@ func (d *HCLDiffer) compareAttributes(baseResource, resource *types.EnvResource,
 }
 
 // tagsのネストした属性を比較
-func (d *HCLDiffer) compareTagAttributes(baseResource *types.EnvResource, baseTags, tags cty.Value, env string) []*types.DiffResult {
+func (d *HCLDiffer) compareTagAttributes(address types.Address, baseTags, tags cty.Value, env string) []*types.DiffResult {
 	if !baseTags.Type().IsObjectType() || !tags.Type().IsObjectType() {
 		return []*types.DiffResult{}
 	}
@@ -280,15 +331,7 @@ func (d *HCLDiffer) compareTagAttributes(baseResource *types.EnvResource, baseTa
 
 	callback := func(tagKey string, baseValue, value cty.Value, baseExists, exists bool) *types.DiffResult {
 		if !baseValue.Equals(value).True() {
-			resourcePath := fmt.Sprintf("%s.%s.tags.%s", baseResource.Type, baseResource.Name, tagKey)
-			return &types.DiffResult{
-				Resource:    fmt.Sprintf("%s.%s", baseResource.Type, baseResource.Name),
-				Environment: env,
-				Path:        fmt.Sprintf("tags.%s", tagKey),
-				Expected:    baseValue,
-				Actual:      value,
-				IsIgnored:   d.ignoreMatcher.IsIgnored(resourcePath),
-			}
+			return d.newDiff(address.WithPath(fmt.Sprintf("tags.%s", tagKey)), env, baseValue, value)
 		}
 		return nil
 	}
@@ -296,28 +339,28 @@ func (d *HCLDiffer) compareTagAttributes(baseResource *types.EnvResource, baseTa
 	return d.compareMapAttributes(baseTagMap, tagMap, callback)
 }
 
-// ネストブロックを比較
-func (d *HCLDiffer) compareBlocks(baseResource, resource *types.EnvResource, env string) []*types.DiffResult {
-	return d.compareBlocksWithPrefix(baseResource, resource, env, "")
+// ネストブロックを比較（addressは比較するブロックのアドレス）
+func (d *HCLDiffer) compareBlocks(address types.Address, baseResource, resource *types.EnvResource, env string) []*types.DiffResult {
+	return d.compareBlockGroups(address, baseResource.Blocks, resource.Blocks, env)
 }
 
-// compareBlocksWithPrefix はリソースプレフィックス付きでネストブロックを比較
-func (d *HCLDiffer) compareBlocksWithPrefix(baseResource, resource *types.EnvResource, env, resourcePrefix string) []*types.DiffResult {
+// compareBlockGroups はブロック型ごとのネストブロックを比較する（ネストブロック内のネストブロックも再帰的に比較する）
+func (d *HCLDiffer) compareBlockGroups(address types.Address, baseBlockGroups, blockGroups map[string][]*types.EnvBlock, env string) []*types.DiffResult {
 	var results []*types.DiffResult
 
 	// 全ブロック型を収集
 	allBlockTypes := make(map[string]bool)
-	for blockType := range baseResource.Blocks {
+	for blockType := range baseBlockGroups {
 		allBlockTypes[blockType] = true
 	}
-	for blockType := range resource.Blocks {
+	for blockType := range blockGroups {
 		allBlockTypes[blockType] = true
 	}
 
 	// 各ブロック型を比較
 	for blockType := range allBlockTypes {
-		baseBlocks := baseResource.Blocks[blockType]
-		blocks := resource.Blocks[blockType]
+		baseBlocks := baseBlockGroups[blockType]
+		blocks := blockGroups[blockType]
 
 		// ブロック数の差分をチェック
 		maxLen := max(len(baseBlocks), len(blocks))
@@ -332,45 +375,20 @@ func (d *HCLDiffer) compareBlocksWithPrefix(baseResource, resource *types.EnvRes
 				block = blocks[i]
 			}
 
-			// リソースパス構築
-			var resourcePath, resourceDisplay, pathDisplay string
-			if resourcePrefix == "" {
-				resourcePath = fmt.Sprintf("%s.%s.%s[%d]", baseResource.Type, baseResource.Name, blockType, i)
-				resourceDisplay = fmt.Sprintf("%s.%s", baseResource.Type, baseResource.Name)
-				pathDisplay = fmt.Sprintf("%s[%d]", blockType, i)
-			} else {
-				resourcePath = fmt.Sprintf("%s.%s.%s.%s[%d]", resourcePrefix, baseResource.Type, baseResource.Name, blockType, i)
-				resourceDisplay = fmt.Sprintf("%s.%s.%s", resourcePrefix, baseResource.Type, baseResource.Name)
-				pathDisplay = fmt.Sprintf("%s[%d]", blockType, i)
-			}
+			blockAddress := address.WithPath(fmt.Sprintf("%s[%d]", blockType, i))
 
 			// ブロック存在差分をチェック
 			if baseBlock == nil && block != nil {
 				// 新しいブロックが追加された
-				diff := &types.DiffResult{
-					Resource:    resourceDisplay,
-					Environment: env,
-					Path:        pathDisplay,
-					Expected:    cty.NullVal(cty.DynamicPseudoType),
-					Actual:      d.formatBlockContent(block),
-					IsIgnored:   d.ignoreMatcher.IsIgnoredWithBlock(resourcePath),
-				}
-				results = append(results, diff)
+				results = append(results, d.newDiff(blockAddress, env, cty.NullVal(cty.DynamicPseudoType), d.formatBlockContent(block)))
 			} else if baseBlock != nil && block == nil {
 				// ブロックが削除された
-				diff := &types.DiffResult{
-					Resource:    resourceDisplay,
-					Environment: env,
-					Path:        pathDisplay,
-					Expected:    d.formatBlockContent(baseBlock),
-					Actual:      cty.NullVal(cty.DynamicPseudoType),
-					IsIgnored:   d.ignoreMatcher.IsIgnoredWithBlock(resourcePath),
-				}
-				results = append(results, diff)
+				results = append(results, d.newDiff(blockAddress, env, d.formatBlockContent(baseBlock), cty.NullVal(cty.DynamicPseudoType)))
 			} else if baseBlock != nil && block != nil {
-				// ブロック内属性を比較
-				blockDiffs := d.compareBlockAttributesWithPrefix(baseResource, baseBlock, block, blockType, i, env, resourcePrefix)
+				// ブロック内属性・ネストブロックを比較
+				blockDiffs := d.compareBlockAttributes(blockAddress, baseBlock, block, env)
 				results = append(results, blockDiffs...)
+				results = append(results, d.compareBlockGroups(blockAddress, baseBlock.Blocks, block.Blocks, env)...)
 			}
 		}
 	}
@@ -378,13 +396,8 @@ func (d *HCLDiffer) compareBlocksWithPrefix(baseResource, resource *types.EnvRes
 	return results
 }
 
-// ブロック内属性を比較
-func (d *HCLDiffer) compareBlockAttributes(resource *types.EnvResource, baseBlock, block *types.EnvBlock, blockType string, index int, env string) []*types.DiffResult {
-	return d.compareBlockAttributesWithPrefix(resource, baseBlock, block, blockType, index, env, "")
-}
-
-// compareBlockAttributesWithPrefix はリソースプレフィックス付きでブロック内属性を比較
-func (d *HCLDiffer) compareBlockAttributesWithPrefix(resource *types.EnvResource, baseBlock, block *types.EnvBlock, blockType string, index int, env, resourcePrefix string) []*types.DiffResult {
+// ブロック内属性を比較（blockAddressはブロックを指すアドレス。例: aws_security_group.web.ingress[0]）
+func (d *HCLDiffer) compareBlockAttributes(blockAddress types.Address, baseBlock, block *types.EnvBlock, env string) []*types.DiffResult {
 	var results []*types.DiffResult
 
 	// 全属性名を収集
@@ -409,26 +422,7 @@ func (d *HCLDiffer) compareBlockAttributesWithPrefix(resource *types.EnvResource
 		}
 
 		if !baseValue.Equals(value).True() {
-			var resourcePath, resourceDisplay, pathDisplay string
-			if resourcePrefix == "" {
-				resourcePath = fmt.Sprintf("%s.%s.%s[%d].%s", resource.Type, resource.Name, blockType, index, attrName)
-				resourceDisplay = fmt.Sprintf("%s.%s", resource.Type, resource.Name)
-				pathDisplay = fmt.Sprintf("%s[%d].%s", blockType, index, attrName)
-			} else {
-				resourcePath = fmt.Sprintf("%s.%s.%s.%s[%d].%s", resourcePrefix, resource.Type, resource.Name, blockType, index, attrName)
-				resourceDisplay = fmt.Sprintf("%s.%s.%s", resourcePrefix, resource.Type, resource.Name)
-				pathDisplay = fmt.Sprintf("%s[%d].%s", blockType, index, attrName)
-			}
-
-			diff := &types.DiffResult{
-				Resource:    resourceDisplay,
-				Environment: env,
-				Path:        pathDisplay,
-				Expected:    baseValue,
-				Actual:      value,
-				IsIgnored:   d.ignoreMatcher.IsIgnoredWithBlockAttribute(resourcePath),
-			}
-			results = append(results, diff)
+			results = append(results, d.newDiff(blockAddress.WithPath(attrName), env, baseValue, value))
 		}
 	}
 
@@ -478,6 +472,18 @@ func (d *HCLDiffer) formatBlockContent(block *types.EnvBlock) cty.Value {
 		}
 	}
 
+	// ネストブロックはブロック型・定義順に表示
+	var blockTypes []string
+	for blockType := range block.Blocks {
+		blockTypes = append(blockTypes, blockType)
+	}
+	sort.Strings(blockTypes)
+	for _, blockType := range blockTypes {
+		for i, nested := range block.Blocks[blockType] {
+			attrs = append(attrs, fmt.Sprintf("%s[%d]: %s", blockType, i, d.formatBlockContent(nested).AsString()))
+		}
+	}
+
 	// 属性をHTMLの<br>タグで改行して表示
 	if len(attrs) == 0 {
 		return cty.StringVal("{}")
@@ -490,10 +496,10 @@ func (d *HCLDiffer) formatBlockContent(block *types.EnvBlock) cty.Value {
 }
 
 // compareNamedAttributes は名前付きアイテム間の属性差分を比較する汎用ヘルパー関数
+// address: 比較するブロックのアドレス（module.vpc, var.region等）
 // baseAttrs, targetAttrs: 比較する属性マップ
-// resourcePrefix, resourceName: リソースパス構築用
 // env: 環境名
-func (d *HCLDiffer) compareNamedAttributes(baseAttrs, targetAttrs map[string]cty.Value, resourcePrefix, resourceName, env string) []*types.DiffResult {
+func (d *HCLDiffer) compareNamedAttributes(address types.Address, baseAttrs, targetAttrs map[string]cty.Value, env string) []*types.DiffResult {
 	var results []*types.DiffResult
 
 	// 全属性名を収集
@@ -518,16 +524,7 @@ func (d *HCLDiffer) compareNamedAttributes(baseAttrs, targetAttrs map[string]cty
 		}
 
 		if !baseValue.Equals(value).True() {
-			resourcePath := fmt.Sprintf("%s.%s.%s", resourcePrefix, resourceName, attrName)
-			diff := &types.DiffResult{
-				Resource:    fmt.Sprintf("%s.%s", resourcePrefix, resourceName),
-				Environment: env,
-				Path:        attrName,
-				Expected:    baseValue,
-				Actual:      value,
-				IsIgnored:   d.ignoreMatcher.IsIgnored(resourcePath),
-			}
-			results = append(results, diff)
+			results = append(results, d.newDiff(address.WithPath(attrName), env, baseValue, value))
 		}
 	}
 
@@ -555,7 +552,7 @@ func (d *HCLDiffer) compareModules(baseModules, envModules []*types.EnvModule, e
 	}
 
 	// 存在差分をチェック
-	existenceDiffs := d.checkExistenceDiff(baseExistenceMap, envExistenceMap, "module", env)
+	existenceDiffs := d.checkExistenceDiff(baseExistenceMap, envExistenceMap, types.KindModule, env)
 	results = append(results, existenceDiffs...)
 
 	// 属性差分をチェック
@@ -563,15 +560,45 @@ func (d *HCLDiffer) compareModules(baseModules, envModules []*types.EnvModule, e
 		if envModule, exists := envModuleMap[name]; exists {
 			attrDiffs := d.compareModuleAttributes(baseModule, envModule, env)
 			results = append(results, attrDiffs...)
+
+			// ローカルモジュールの内部構成を比較
+			childDiffs := d.compareModuleChildren(baseModule, envModule, env)
+			results = append(results, childDiffs...)
 		}
 	}
 
 	return results
 }
 
+// compareModuleChildren はローカルモジュールを具体化した内部構成を module.<name>.<type>.<name> のパスで比較する
+// 片方の環境のみ内部構成を持つ場合（sourceがローカル以外に変わった等）は空の構成と比較する
+func (d *HCLDiffer) compareModuleChildren(baseModule, envModule *types.EnvModule, env string) []*types.DiffResult {
+	if baseModule.Children == nil && envModule.Children == nil {
+		return nil
+	}
+
+	childDiffer := &HCLDiffer{ignoreMatcher: d.ignoreMatcher.WithModule(baseModule.Name)}
+	results := childDiffer.compareEnvResources(childrenOrEmpty(baseModule.Children), childrenOrEmpty(envModule.Children), env)
+
+	for _, diff := range results {
+		diff.Resource = diff.Address().InModule(baseModule.Name).Resource()
+	}
+	return results
+}
+
+// childrenOrEmpty はnilの場合に空のEnvResourcesを返す
+func childrenOrEmpty(children *types.EnvResources) *types.EnvResources {
+	if children != nil {
+		return children
+	}
+	return &types.EnvResources{}
+}
+
 // compareModuleAttributes はモジュール属性間の差分を比較
 func (d *HCLDiffer) compareModuleAttributes(baseModule, envModule *types.EnvModule, env string) []*types.DiffResult {
-	return d.compareNamedAttributes(baseModule.Attrs, envModule.Attrs, "module", baseModule.Name, env)
+	results := d.compareNamedAttributes(baseModule.Address(), baseModule.Attrs, envModule.Attrs, env)
+	d.classifyModuleDiffs(baseModule, envModule, results)
+	return results
 }
 
 // compareLocals はローカル変数間の差分を比較
@@ -595,23 +622,14 @@ func (d *HCLDiffer) compareLocals(baseLocals, envLocals []*types.EnvLocal, env s
 	}
 
 	// 存在差分をチェック
-	existenceDiffs := d.checkExistenceDiff(baseExistenceMap, envExistenceMap, "local", env)
+	existenceDiffs := d.checkExistenceDiff(baseExistenceMap, envExistenceMap, types.KindLocal, env)
 	results = append(results, existenceDiffs...)
 
 	// 値差分をチェック
 	for name, baseLocal := range baseLocalMap {
 		if envLocal, exists := envLocalMap[name]; exists {
 			if !baseLocal.Value.Equals(envLocal.Value).True() {
-				resourcePath := fmt.Sprintf("local.%s", name)
-				diff := &types.DiffResult{
-					Resource:    fmt.Sprintf("local.%s", name),
-					Environment: env,
-					Path:        "",
-					Expected:    baseLocal.Value,
-					Actual:      envLocal.Value,
-					IsIgnored:   d.ignoreMatcher.IsIgnored(resourcePath),
-				}
-				results = append(results, diff)
+				results = append(results, d.newDiff(types.BlockAddress(types.KindLocal, name), env, baseLocal.Value, envLocal.Value))
 			}
 		}
 	}
@@ -640,7 +658,7 @@ func (d *HCLDiffer) compareVariables(baseVariables, envVariables []*types.EnvVar
 	}
 
 	// 存在差分をチェック
-	existenceDiffs := d.checkExistenceDiff(baseExistenceMap, envExistenceMap, "var", env)
+	existenceDiffs := d.checkExistenceDiff(baseExistenceMap, envExistenceMap, types.KindVariable, env)
 	results = append(results, existenceDiffs...)
 
 	// 属性差分をチェック
@@ -656,7 +674,7 @@ func (d *HCLDiffer) compareVariables(baseVariables, envVariables []*types.EnvVar
 
 // compareVariableAttributes は変数属性間の差分を比較
 func (d *HCLDiffer) compareVariableAttributes(baseVariable, envVariable *types.EnvVariable, env string) []*types.DiffResult {
-	return d.compareNamedAttributes(baseVariable.Attrs, envVariable.Attrs, "var", baseVariable.Name, env)
+	return d.compareNamedAttributes(baseVariable.Address(), baseVariable.Attrs, envVariable.Attrs, env)
 }
 
 // compareOutputs は出力変数間の差分を比較
@@ -680,7 +698,7 @@ func (d *HCLDiffer) compareOutputs(baseOutputs, envOutputs []*types.EnvOutput, e
 	}
 
 	// 存在差分をチェック
-	existenceDiffs := d.checkExistenceDiff(baseExistenceMap, envExistenceMap, "output", env)
+	existenceDiffs := d.checkExistenceDiff(baseExistenceMap, envExistenceMap, types.KindOutput, env)
 	results = append(results, existenceDiffs...)
 
 	// 属性差分をチェック
@@ -696,7 +714,7 @@ func (d *HCLDiffer) compareOutputs(baseOutputs, envOutputs []*types.EnvOutput, e
 
 // compareOutputAttributes は出力変数属性間の差分を比較
 func (d *HCLDiffer) compareOutputAttributes(baseOutput, envOutput *types.EnvOutput, env string) []*types.DiffResult {
-	return d.compareNamedAttributes(baseOutput.Attrs, envOutput.Attrs, "output", baseOutput.Name, env)
+	return d.compareNamedAttributes(baseOutput.Address(), baseOutput.Attrs, envOutput.Attrs, env)
 }
 
 // compareDataSources はデータソース間の差分を比較
@@ -707,7 +725,7 @@ func (d *HCLDiffer) compareDataSources(baseDataSources, envDataSources []*types.
 	baseDataMap := make(map[string]*types.EnvData)
 	baseExistenceMap := make(map[string]bool)
 	for _, data := range baseDataSources {
-		key := fmt.Sprintf("%s.%s", data.Type, data.Name)
+		key := data.Address().String()
 		baseDataMap[key] = data
 		baseExistenceMap[key] = true
 	}
@@ -716,12 +734,12 @@ func (d *HCLDiffer) compareDataSources(baseDataSources, envDataSources []*types.
 	envDataMap := make(map[string]*types.EnvData)
 	envExistenceMap := make(map[string]bool)
 	for _, data := range envDataSources {
-		key := fmt.Sprintf("%s.%s", data.Type, data.Name)
+		key := data.Address().String()
 		envDataMap[key] = data
 		envExistenceMap[key] = true
 	}
 
-	// 存在差分をチェック（"data"プレフィックスを付けるため、存在差分チェック前にマップ変換）
+	// 存在差分をチェック（dataソースはインスタンスキーを持つため、名前のみのcheckExistenceDiffは使わない）
 	allKeys := make(map[string]bool)
 	for key := range baseExistenceMap {
 		allKeys[key] = true
@@ -735,87 +753,23 @@ func (d *HCLDiffer) compareDataSources(baseDataSources, envDataSources []*types.
 		envExists := envExistenceMap[key]
 
 		if baseExists != envExists {
-			resourcePath := fmt.Sprintf("data.%s", key)
-			diff := &types.DiffResult{
-				Resource:    resourcePath,
-				Environment: env,
-				Path:        "",
-				Expected:    cty.BoolVal(baseExists),
-				Actual:      cty.BoolVal(envExists),
-				IsIgnored:   d.ignoreMatcher.IsIgnored(resourcePath),
+			data := baseDataMap[key]
+			if data == nil {
+				data = envDataMap[key]
 			}
-			results = append(results, diff)
+			results = append(results, d.newDiff(data.Address(), env, cty.BoolVal(baseExists), cty.BoolVal(envExists)))
 		}
 	}
 
 	// 属性差分とブロック差分をチェック
 	for key, baseData := range baseDataMap {
 		if envData, exists := envDataMap[key]; exists {
-			// 属性差分を比較
-			attrDiffs := d.compareDataSourceAttributes(baseData, envData, env)
-			results = append(results, attrDiffs...)
-
-			// ブロック差分を比較（EnvDataをEnvResourceに変換）
-			baseResource := &types.EnvResource{
-				Type:   baseData.Type,
-				Name:   baseData.Name,
-				Attrs:  baseData.Attrs,
-				Blocks: baseData.Blocks,
-			}
-			envResource := &types.EnvResource{
-				Type:   envData.Type,
-				Name:   envData.Name,
-				Attrs:  envData.Attrs,
-				Blocks: envData.Blocks,
-			}
-			blockDiffs := d.compareBlocksWithPrefix(baseResource, envResource, env, "data")
-			results = append(results, blockDiffs...)
+			// resourceと同じく属性（tagsのネストを含む）とネストブロックを比較する
+			address := baseData.Address()
+			results = append(results, d.compareAttributes(address, dataAsResource(baseData), dataAsResource(envData), env)...)
+			results = append(results, d.compareBlocks(address, dataAsResource(baseData), dataAsResource(envData), env)...)
 		}
 	}
 
 	return results
 }
-
-// compareDataSourceAttributes はデータソース属性間の差分を比較
-func (d *HCLDiffer) compareDataSourceAttributes(baseData, envData *types.EnvData, env string) []*types.DiffResult {
-	var results []*types.DiffResult
-
-	// 全属性名を収集
-	allAttrNames := make(map[string]bool)
-	for name := range baseData.Attrs {
-		allAttrNames[name] = true
-	}
-	for name := range envData.Attrs {
-		allAttrNames[name] = true
-	}
-
-	// 各属性を比較
-	for attrName := range allAttrNames {
-		baseValue, baseExists := baseData.Attrs[attrName]
-		value, exists := envData.Attrs[attrName]
-
-		if !baseExists {
-			baseValue = cty.NullVal(cty.DynamicPseudoType)
-		}
-		if !exists {
-			value = cty.NullVal(cty.DynamicPseudoType)
-		}
-
-		if !baseValue.Equals(value).True() {
-			resourcePath := fmt.Sprintf("data.%s.%s.%s", baseData.Type, baseData.Name, attrName)
-			diff := &types.DiffResult{
-				Resource:    fmt.Sprintf("data.%s.%s", baseData.Type, baseData.Name),
-				Environment: env,
-				Path:        attrName,
-				Expected:    baseValue,
-				Actual:      value,
-				IsIgnored:   d.ignoreMatcher.IsIgnored(resourcePath),
-			}
-			results = append(results, diff)
-		}
-	}
-
-	return results
-}
-
-
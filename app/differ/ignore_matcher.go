@@ -2,7 +2,9 @@ package differ
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/Mkamono/tfspec/app/types"
 )
@@ -10,39 +12,135 @@ import (
 // IgnoreMatcher は無視ルールの判定を担当する
 type IgnoreMatcher struct {
 	rules          []string
+	allowRules     map[string][]string // allowオプション付きルール（パス -> 許容する差分の分類）
 	validatedRules map[string]bool
-	warnings       []string
+	matchedRules   map[string]bool // 差分にマッチしたルール
+	staleRules     []string        // 実際のリソース構成に存在しないルール
+	expiredRules   []string // expiresオプションの期限が切れたルール
+	warnings       *[]string // WithModuleで生成したIgnoreMatcherと共有する
+	modules        []string // 判定対象のアドレスの外側のモジュール（モジュール内部の比較用）
 }
 
+// expiresLayout はexpiresオプションの日付形式
+const expiresLayout = "2006-01-02"
+
+// NewIgnoreMatcher はルール文字列からIgnoreMatcherを生成する
+// ルールは「パス オプション...」の形式で、オプションは key=value で指定する
+// （例: module.vpc.version allow=patch, aws_instance.web.ami expires=2025-12-31）
 func NewIgnoreMatcher(rules []string) *IgnoreMatcher {
-	return &IgnoreMatcher{
-		rules:          rules,
+	m := &IgnoreMatcher{
+		allowRules:     make(map[string][]string),
 		validatedRules: make(map[string]bool),
-		warnings:       make([]string, 0),
+		matchedRules:   make(map[string]bool),
+		warnings:       &[]string{},
+	}
+	today := time.Now().Format(expiresLayout)
+
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		if len(fields) == 0 {
+			continue
+		}
+
+		path := fields[0]
+		var allow []string
+		hasAllow, expired := false, false
+		for _, option := range fields[1:] {
+			key, value, _ := strings.Cut(option, "=")
+			switch key {
+			case "allow":
+				hasAllow = true
+				for _, classification := range strings.Split(value, ",") {
+					if !isKnownModuleChange(classification) {
+						m.warn("無視ルール '%s' のallowに不明な分類 '%s' が指定されています", rule, classification)
+						continue
+					}
+					allow = append(allow, classification)
+				}
+			case "expires":
+				if _, err := time.Parse(expiresLayout, value); err != nil {
+					m.warn("無視ルール '%s' のexpiresは YYYY-MM-DD 形式で指定してください", rule)
+					continue
+				}
+				// 期限日の翌日から無効になる
+				expired = expired || value < today
+			default:
+				m.warn("無視ルール '%s' に不明なオプション '%s' が指定されています", rule, option)
+			}
+		}
+
+		// 期限切れのルールは差分を無視しない
+		if expired {
+			m.expiredRules = append(m.expiredRules, path)
+			m.warn("無視ルール '%s' は期限切れのため適用されません", rule)
+			continue
+		}
+
+		// allowオプション付きのルールは分類が一致する差分のみを無視する
+		if hasAllow {
+			m.allowRules[path] = append(m.allowRules[path], allow...)
+			continue
+		}
+		m.rules = append(m.rules, path)
 	}
+
+	return m
 }
 
-// IsIgnored はリソース・属性パスが無視ルールにマッチするかチェックする
-func (m *IgnoreMatcher) IsIgnored(resourcePath string) bool {
+// WithModule はローカルモジュールの内部構成の差分を判定するIgnoreMatcherを返す
+// 判定するアドレスの外側にモジュールを加え、module.<name>.aws_instance.web のようなルールと照合する
+func (m *IgnoreMatcher) WithModule(name string) *IgnoreMatcher {
+	return &IgnoreMatcher{
+		rules:          m.rules,
+		allowRules:     m.allowRules,
+		validatedRules: m.validatedRules,
+		matchedRules:   m.matchedRules,
+		warnings:       m.warnings,
+		modules:        append(append([]string{}, m.modules...), name),
+	}
+}
+
+// IsIgnored はアドレス（属性パスを含む）が無視ルールにマッチするかチェックする
+// マッチした全てのルールを差分があったルールとして記録する
+func (m *IgnoreMatcher) IsIgnored(address types.Address) bool {
+	path := m.fullPath(address)
+	ignored := false
 	for _, rule := range m.rules {
-		if rule == resourcePath {
-			return true
-		}
-		if m.isChildPath(resourcePath, rule) {
-			return true
+		if rule == path || m.isChildPath(path, rule) {
+			m.matchedRules[rule] = true
+			ignored = true
 		}
 	}
-	return false
+	return ignored
 }
 
-// IsIgnoredWithBlock はブロック情報を考慮した無視判定を行う（互換性のためのエイリアス）
-func (m *IgnoreMatcher) IsIgnoredWithBlock(resourcePath string) bool {
-	return m.IsIgnored(resourcePath)
+// IsAllowedChange は差分の分類（patch, minor等）がallowオプション付きルールで許容されているかチェックする
+func (m *IgnoreMatcher) IsAllowedChange(address types.Address, classification string) bool {
+	if classification == "" {
+		return false
+	}
+
+	path := m.fullPath(address)
+	for rule, allowed := range m.allowRules {
+		if rule != path && !m.isChildPath(path, rule) {
+			continue
+		}
+		m.matchedRules[rule] = true
+		for _, allowedClassification := range allowed {
+			if moduleChangeAllows(allowedClassification, classification) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-// IsIgnoredWithBlockAttribute はブロック属性の無視判定を行う（互換性のためのエイリアス）
-func (m *IgnoreMatcher) IsIgnoredWithBlockAttribute(resourcePath string) bool {
-	return m.IsIgnored(resourcePath)
+// fullPath はモジュールの内部構成の場合はモジュールを含めたアドレスの文字列を返す
+func (m *IgnoreMatcher) fullPath(address types.Address) string {
+	for i := len(m.modules) - 1; i >= 0; i-- {
+		address = address.InModule(m.modules[i])
+	}
+	return address.String()
 }
 
 // isChildPath は指定されたパスが親ルールの子パスかどうかをチェックする
@@ -50,71 +148,69 @@ func (m *IgnoreMatcher) isChildPath(resourcePath, parentRule string) bool {
 	return strings.HasPrefix(resourcePath, parentRule+".")
 }
 
-// ValidateRules は与えられたリソースデータに対して無視ルールの検証を行う
-func (m *IgnoreMatcher) ValidateRules(envs map[string]map[string]*types.EnvResource) {
-	for _, rule := range m.rules {
+// ValidateRules は無視ルールが少なくとも1つの環境の構成に存在するか検証する
+func (m *IgnoreMatcher) ValidateRules(envs map[string]*types.EnvResources) {
+	rules := append([]string{}, m.rules...)
+	for rule := range m.allowRules {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules[len(m.rules):]) // 警告の順序を一定にする
+
+	for _, rule := range rules {
 		if m.isValidRule(rule, envs) {
 			m.validatedRules[rule] = true
 		} else {
-			m.warnings = append(m.warnings, fmt.Sprintf("無視ルール '%s' は実際のリソース構成に存在しません", rule))
+			m.staleRules = append(m.staleRules, rule)
+			m.warn("無視ルール '%s' は実際のリソース構成に存在しません", rule)
 		}
 	}
 }
 
-// GetWarnings は検証で発見された警告を返す
-func (m *IgnoreMatcher) GetWarnings() []string {
-	return m.warnings
-}
-
-// isValidRule は無視ルールが実際のリソース構成に存在するかチェックする
-func (m *IgnoreMatcher) isValidRule(rule string, envs map[string]map[string]*types.EnvResource) bool {
-	parts := strings.Split(rule, ".")
-	if len(parts) < 2 {
-		return false
+// CheckUnmatchedRules は構成に存在するが、どの差分にもマッチしなかったルールを警告する
+// （ルールの対象が全ての環境で同じ値になり、無視する必要がなくなった場合）
+func (m *IgnoreMatcher) CheckUnmatchedRules() {
+	rules := append([]string{}, m.rules...)
+	for rule := range m.allowRules {
+		rules = append(rules, rule)
 	}
+	sort.Strings(rules[len(m.rules):]) // 警告の順序を一定にする
 
-	resourceType := parts[0]
-	resourceName := parts[1]
-	resourceKey := resourceType + "." + resourceName
-
-	// 少なくとも1つの環境でリソースが存在するかチェック
-	for _, envResources := range envs {
-		if resource, exists := envResources[resourceKey]; exists {
-			if len(parts) == 2 {
-				// リソース自体の指定
-				return true
-			}
-
-			// 属性の存在チェック
-			attributePath := strings.Join(parts[2:], ".")
-			return m.hasAttribute(resource, attributePath)
+	for _, rule := range rules {
+		if m.validatedRules[rule] && !m.matchedRules[rule] {
+			m.warn("無視ルール '%s' は構成に存在しますが、全ての環境で同じ値のため差分がありません", rule)
 		}
 	}
+}
 
-	return false
+// GetStaleRules は実際のリソース構成に存在しないルールを返す
+func (m *IgnoreMatcher) GetStaleRules() []string {
+	return m.staleRules
 }
 
-// hasAttribute は指定されたリソースに属性が存在するかチェックする
-func (m *IgnoreMatcher) hasAttribute(resource *types.EnvResource, attributePath string) bool {
-	parts := strings.Split(attributePath, ".")
+// GetExpiredRules はexpiresオプションの期限が切れたルールを返す
+func (m *IgnoreMatcher) GetExpiredRules() []string {
+	return m.expiredRules
+}
 
-	// 単純な属性チェック（ここでは基本的な属性のみチェック）
-	if len(parts) == 1 {
-		// 基本属性の存在チェック
-		attr := parts[0]
-		_, exists := resource.Attrs[attr]
-		return exists
-	}
+// GetWarnings は検証で発見された警告を返す
+func (m *IgnoreMatcher) GetWarnings() []string {
+	return *m.warnings
+}
 
-	// ネストした属性の場合（tags.Environment等）
-	if len(parts) >= 2 && parts[0] == "tags" {
-		if tagsVal, exists := resource.Attrs["tags"]; exists && tagsVal.Type().IsObjectType() {
-			tagKey := parts[1]
-			tagsMap := tagsVal.AsValueMap()
-			_, tagExists := tagsMap[tagKey]
-			return tagExists
+// warn は警告を追加する（モジュール内部の判定中に追加した警告も呼び出し元のIgnoreMatcherから取得できる）
+func (m *IgnoreMatcher) warn(format string, args ...any) {
+	*m.warnings = append(*m.warnings, fmt.Sprintf(format, args...))
+}
+
+// isValidRule は無視ルールが少なくとも1つの環境の構成（モジュールの内部構成を含む全てのブロック）に存在するかチェックする
+// ルールはブロックのアドレスと属性パスの区切りが一意に決まらないため、解釈できる全ての候補で検索する
+func (m *IgnoreMatcher) isValidRule(rule string, envs map[string]*types.EnvResources) bool {
+	for _, address := range types.ParseAttributeAddress(rule) {
+		for _, envResources := range envs {
+			if _, exists := envResources.LookupValue(address); exists {
+				return true
+			}
 		}
 	}
-
 	return false
-}
\ No newline at end of file
+}
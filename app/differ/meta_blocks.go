@@ -0,0 +1,83 @@
+package differ
+
+import (
+	"github.com/Mkamono/tfspec/app/types"
+)
+
+// compareMetaBlocks はmoved/import/removed/checkブロックの差分を比較する
+// 各ブロックは moved.<from>, import.<to>, removed.<from>, check.<name> のアドレスで識別する
+func (d *HCLDiffer) compareMetaBlocks(baseEnvResources, envResources *types.EnvResources, env string) []*types.DiffResult {
+	var results []*types.DiffResult
+
+	results = append(results, d.compareAddressedBlocks(types.KindMoved, movedAsResources(baseEnvResources.Moved), movedAsResources(envResources.Moved), env)...)
+	results = append(results, d.compareAddressedBlocks(types.KindImport, importsAsResources(baseEnvResources.Imports), importsAsResources(envResources.Imports), env)...)
+	results = append(results, d.compareAddressedBlocks(types.KindRemoved, removedAsResources(baseEnvResources.Removed), removedAsResources(envResources.Removed), env)...)
+	results = append(results, d.compareAddressedBlocks(types.KindCheck, checksAsResources(baseEnvResources.Checks), checksAsResources(envResources.Checks), env)...)
+
+	return results
+}
+
+// compareAddressedBlocks はアドレスで識別されるブロック群の存在差分・属性差分・ネストブロック差分を比較する
+func (d *HCLDiffer) compareAddressedBlocks(kind string, baseBlocks, envBlocks map[string]*types.EnvResource, env string) []*types.DiffResult {
+	var results []*types.DiffResult
+
+	baseExistenceMap := make(map[string]bool)
+	for name := range baseBlocks {
+		baseExistenceMap[name] = true
+	}
+	envExistenceMap := make(map[string]bool)
+	for name := range envBlocks {
+		envExistenceMap[name] = true
+	}
+
+	// 存在差分をチェック
+	existenceDiffs := d.checkExistenceDiff(baseExistenceMap, envExistenceMap, kind, env)
+	results = append(results, existenceDiffs...)
+
+	// 属性差分とネストブロック差分をチェック
+	for name, baseBlock := range baseBlocks {
+		if envBlock, exists := envBlocks[name]; exists {
+			address := types.BlockAddress(kind, name)
+			results = append(results, d.compareNamedAttributes(address, baseBlock.Attrs, envBlock.Attrs, env)...)
+			results = append(results, d.compareBlocks(address, baseBlock, envBlock, env)...)
+		}
+	}
+
+	return results
+}
+
+// movedAsResources はmovedブロックをfromアドレスをキーとするEnvResourceのマップに変換する
+func movedAsResources(movedBlocks []*types.EnvMoved) map[string]*types.EnvResource {
+	resources := make(map[string]*types.EnvResource)
+	for _, moved := range movedBlocks {
+		resources[moved.From] = &types.EnvResource{Type: "moved", Name: moved.From, Attrs: moved.Attrs}
+	}
+	return resources
+}
+
+// importsAsResources はimportブロックをtoアドレスをキーとするEnvResourceのマップに変換する
+func importsAsResources(imports []*types.EnvImport) map[string]*types.EnvResource {
+	resources := make(map[string]*types.EnvResource)
+	for _, imp := range imports {
+		resources[imp.To] = &types.EnvResource{Type: "import", Name: imp.To, Attrs: imp.Attrs}
+	}
+	return resources
+}
+
+// removedAsResources はremovedブロックをfromアドレスをキーとするEnvResourceのマップに変換する
+func removedAsResources(removedBlocks []*types.EnvRemoved) map[string]*types.EnvResource {
+	resources := make(map[string]*types.EnvResource)
+	for _, removed := range removedBlocks {
+		resources[removed.From] = &types.EnvResource{Type: "removed", Name: removed.From, Attrs: removed.Attrs, Blocks: removed.Blocks}
+	}
+	return resources
+}
+
+// checksAsResources はcheckブロックを名前をキーとするEnvResourceのマップに変換する
+func checksAsResources(checks []*types.EnvCheck) map[string]*types.EnvResource {
+	resources := make(map[string]*types.EnvResource)
+	for _, check := range checks {
+		resources[check.Name] = &types.EnvResource{Type: "check", Name: check.Name, Attrs: check.Attrs, Blocks: check.Blocks}
+	}
+	return resources
+}
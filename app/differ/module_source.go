@@ -0,0 +1,197 @@
+package differ
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/hashicorp/go-version"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// moduleChangeLevels はバージョン差分の分類の大きさ（allow=minor はpatchも許容する）
+var moduleChangeLevels = map[string]int{
+	types.ModuleChangePatch: 1,
+	types.ModuleChangeMinor: 2,
+	types.ModuleChangeMajor: 3,
+}
+
+// registrySourcePattern はレジストリモジュールのアドレス（[ホスト名/]名前空間/名前/プロバイダ）
+var registrySourcePattern = regexp.MustCompile(`^([a-zA-Z0-9.-]+\.[a-zA-Z]+/)?[a-zA-Z0-9-_]+/[a-zA-Z0-9-_]+/[a-zA-Z0-9-_]+(//.*)?$`)
+
+// moduleSource はモジュールのsourceを種類・アドレス・バージョン（git ref）に分解したもの
+type moduleSource struct {
+	kind    string // local, registry, git, other
+	address string // バージョン・refを除いたアドレス
+	ref     string // gitの?ref=の値
+}
+
+// parseModuleSource はモジュールのsource文字列を解析する
+func parseModuleSource(source string) moduleSource {
+	switch {
+	case types.IsLocalModuleSource(source):
+		return moduleSource{kind: "local", address: path.Clean(source)}
+	case strings.HasPrefix(source, "git::") || strings.HasPrefix(source, "github.com/") ||
+		strings.HasPrefix(source, "git@") || strings.HasPrefix(source, "bitbucket.org/"):
+		address, query, _ := strings.Cut(strings.TrimPrefix(source, "git::"), "?")
+		var ref string
+		for _, param := range strings.Split(query, "&") {
+			if value, found := strings.CutPrefix(param, "ref="); found {
+				ref = value
+			}
+		}
+		return moduleSource{kind: "git", address: strings.TrimSuffix(address, ".git"), ref: ref}
+	case registrySourcePattern.MatchString(source):
+		return moduleSource{kind: "registry", address: strings.TrimPrefix(source, "registry.terraform.io/")}
+	default:
+		return moduleSource{kind: "other", address: source}
+	}
+}
+
+// classifyModuleChange はモジュールのsource/versionの差分を分類する
+// 別のモジュール（module）、バージョンのずれ（major/minor/patch）、制約と固定の違い（constraint）のいずれかを返す
+// 分類できない場合は空文字を返す
+func classifyModuleChange(baseAttrs, envAttrs map[string]cty.Value) string {
+	baseSource, baseOk := stringAttr(baseAttrs, "source")
+	envSource, envOk := stringAttr(envAttrs, "source")
+	if !baseOk || !envOk {
+		return ""
+	}
+
+	base := parseModuleSource(baseSource)
+	env := parseModuleSource(envSource)
+	if base.kind != env.kind || base.address != env.address {
+		return types.ModuleChangeModule
+	}
+
+	// gitはref、それ以外はversion引数をバージョンとして扱う
+	baseVersion, envVersion := base.ref, env.ref
+	if base.kind != "git" {
+		baseVersion, _ = stringAttr(baseAttrs, "version")
+		envVersion, _ = stringAttr(envAttrs, "version")
+	}
+	if baseVersion == envVersion {
+		return ""
+	}
+
+	return classifyVersionChange(baseVersion, envVersion)
+}
+
+// classifyVersionChange は2つのバージョン（または制約）の差分を分類する
+func classifyVersionChange(baseVersion, envVersion string) string {
+	baseExact, baseErr := version.NewVersion(baseVersion)
+	envExact, envErr := version.NewVersion(envVersion)
+
+	switch {
+	case baseErr == nil && envErr == nil:
+		return versionSkew(baseExact, envExact)
+	case baseErr == nil:
+		return classifyConstraintChange(envVersion, baseExact)
+	case envErr == nil:
+		return classifyConstraintChange(baseVersion, envExact)
+	}
+
+	// 両方とも制約の場合は下限のバージョンで比較する
+	baseLower, baseOk := constraintVersion(baseVersion)
+	envLower, envOk := constraintVersion(envVersion)
+	if !baseOk || !envOk {
+		return ""
+	}
+	if skew := versionSkew(baseLower, envLower); skew != "" {
+		return skew
+	}
+	return types.ModuleChangeConstraint
+}
+
+// classifyConstraintChange は制約と固定バージョンの差分を分類する
+// 固定バージョンが制約を満たす場合はconstraint、満たさない場合は制約の下限とのずれで分類する
+func classifyConstraintChange(constraint string, exact *version.Version) string {
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return ""
+	}
+	if constraints.Check(exact) {
+		return types.ModuleChangeConstraint
+	}
+
+	lower, ok := constraintVersion(constraint)
+	if !ok {
+		return ""
+	}
+	if skew := versionSkew(lower, exact); skew != "" {
+		return skew
+	}
+	return types.ModuleChangeConstraint
+}
+
+// constraintVersion は制約（~> 3.0, >= 1.2.0等）の最初の条件のバージョンを返す
+func constraintVersion(constraint string) (*version.Version, bool) {
+	first, _, _ := strings.Cut(constraint, ",")
+	first = strings.TrimLeft(strings.TrimSpace(first), "~><=!= ")
+	v, err := version.NewVersion(first)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// versionSkew は2つのバージョンのずれ（major/minor/patch）を返す（同一の場合は空文字）
+func versionSkew(base, env *version.Version) string {
+	baseSegments, envSegments := base.Segments(), env.Segments()
+	for i, classification := range []string{types.ModuleChangeMajor, types.ModuleChangeMinor, types.ModuleChangePatch} {
+		if baseSegments[i] != envSegments[i] {
+			return classification
+		}
+	}
+	if base.Prerelease() != env.Prerelease() {
+		return types.ModuleChangePatch
+	}
+	return ""
+}
+
+// isKnownModuleChange はallowオプションに指定できる分類かどうかを判定する
+func isKnownModuleChange(classification string) bool {
+	if _, exists := moduleChangeLevels[classification]; exists {
+		return true
+	}
+	return classification == types.ModuleChangeConstraint || classification == types.ModuleChangeModule
+}
+
+// moduleChangeAllows はallowに指定された分類が差分の分類を許容するかどうかを判定する
+// major/minor/patchは指定したレベル以下のずれを許容し、constraint/moduleは同じ分類のみを許容する
+func moduleChangeAllows(allowed, classification string) bool {
+	allowedLevel, allowedIsLevel := moduleChangeLevels[allowed]
+	level, isLevel := moduleChangeLevels[classification]
+	if allowedIsLevel && isLevel {
+		return level <= allowedLevel
+	}
+	return allowed == classification
+}
+
+// stringAttr は文字列の属性値を取得する
+func stringAttr(attrs map[string]cty.Value, name string) (string, bool) {
+	value, exists := attrs[name]
+	if !exists || value.IsNull() || !value.IsKnown() || value.Type() != cty.String {
+		return "", false
+	}
+	return value.AsString(), true
+}
+
+// classifyModuleDiffs はモジュールのsource/version差分に分類を付与し、allowオプションで許容された差分を無視扱いにする
+func (d *HCLDiffer) classifyModuleDiffs(baseModule, envModule *types.EnvModule, diffs []*types.DiffResult) {
+	classification := classifyModuleChange(baseModule.Attrs, envModule.Attrs)
+	if classification == "" {
+		return
+	}
+
+	for _, diff := range diffs {
+		if diff.Path != "source" && diff.Path != "version" {
+			continue
+		}
+		diff.Classification = classification
+		if !diff.IsIgnored {
+			diff.IsIgnored = d.ignoreMatcher.IsAllowedChange(types.BlockAddress(types.KindModule, baseModule.Name).WithPath(diff.Path), classification)
+		}
+	}
+}
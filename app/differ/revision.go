@@ -0,0 +1,99 @@
+package differ
+
+import (
+	"fmt"
+
+	"github.com/Mkamono/tfspec/app/types"
+)
+
+// CompareWithBase は基準ref時点の差分と現在の差分を比較する
+// 現在の差分のうち新規・値が変わったものと、基準ref時点にはあったが解消された差分を返す
+func CompareWithBase(baseDiffs, diffs []*types.DiffResult) ([]*types.DiffResult, []*types.DiffResult) {
+	baseDiffMap := make(map[string]*types.DiffResult)
+	for _, diff := range baseDiffs {
+		baseDiffMap[diffKey(diff)] = diff
+	}
+
+	var changed []*types.DiffResult
+	currentKeys := make(map[string]bool)
+	for _, diff := range diffs {
+		key := diffKey(diff)
+		currentKeys[key] = true
+
+		baseDiff, exists := baseDiffMap[key]
+		switch {
+		case !exists:
+			diff.RefChange = types.RefChangeNew
+		case diffFingerprint(baseDiff) != diffFingerprint(diff):
+			diff.RefChange = types.RefChangeChanged
+		default:
+			continue // 基準ref時点から変わっていない差分
+		}
+		changed = append(changed, diff)
+	}
+
+	var resolved []*types.DiffResult
+	for _, diff := range baseDiffs {
+		if !currentKeys[diffKey(diff)] {
+			diff.RefChange = types.RefChangeResolved
+			resolved = append(resolved, diff)
+		}
+	}
+
+	return changed, resolved
+}
+
+// CompareRuns は前回と今回の実行の差分を比較する（watchモード用）
+// 新たに検出された差分・値が変わった差分・検出されなくなった差分を返す
+// 無視ルールの追加・削除も分かるよう、意図的な差分かどうかも識別に含める
+func CompareRuns(previous, current []*types.DiffResult) ([]*types.DiffResult, []*types.DiffResult, []*types.DiffResult) {
+	previousMap := make(map[string]*types.DiffResult)
+	for _, diff := range previous {
+		previousMap[runKey(diff)] = diff
+	}
+
+	var appeared, changed []*types.DiffResult
+	currentKeys := make(map[string]bool)
+	for _, diff := range current {
+		key := runKey(diff)
+		currentKeys[key] = true
+
+		previousDiff, exists := previousMap[key]
+		switch {
+		case !exists:
+			appeared = append(appeared, diff)
+		case diffFingerprint(previousDiff) != diffFingerprint(diff):
+			changed = append(changed, diff)
+		}
+	}
+
+	var disappeared []*types.DiffResult
+	for _, diff := range previous {
+		if !currentKeys[runKey(diff)] {
+			disappeared = append(disappeared, diff)
+		}
+	}
+
+	return appeared, changed, disappeared
+}
+
+// runKey は差分を意図的な差分かどうかを含めて識別するキーを返す
+func runKey(diff *types.DiffResult) string {
+	return fmt.Sprintf("%s|%t", diffKey(diff), diff.IsIgnored)
+}
+
+// diffKey は差分を環境・リソース・属性パスで識別するキーを返す
+func diffKey(diff *types.DiffResult) string {
+	return diff.Environment + "|" + diff.Resource + "|" + diff.Path
+}
+
+// diffFingerprint は差分の値（基準環境の値と比較環境の値）を表す文字列を返す
+func diffFingerprint(diff *types.DiffResult) string {
+	return diff.Expected.GoString() + "|" + diff.Actual.GoString()
+}
+
+// CompareRevisions は同一環境の2つの時点の構成を比較する
+// before を基準とし、差分の環境名は label とする
+func (d *HCLDiffer) CompareRevisions(before, after *types.EnvResources, label string) []*types.DiffResult {
+	return d.compareEnvResources(before, after, label)
+}
@@ -0,0 +1,117 @@
+package differ
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Mkamono/tfspec/app/types"
+)
+
+// 重要度ルールの対象の指定方法
+const (
+	severityTargetType = "type:" // リソースタイプ（例: type:aws_kms_key）
+	severityTargetAttr = "attr:" // 属性名（例: attr:deletion_protection）
+)
+
+// SeverityMatcher は重要度ルールに従って差分に重要度を割り当てる
+type SeverityMatcher struct {
+	rules    []severityRule
+	warnings []string
+}
+
+// severityRule は「対象 重要度」形式の重要度ルール
+type severityRule struct {
+	resourceType string         // リソースタイプ指定の場合のみ
+	attribute    string         // 属性名指定の場合のみ
+	pattern      *regexp.Regexp // パス指定の場合のみ（*は1階層内の任意の文字列）
+	severity     string
+}
+
+// NewSeverityMatcher はルール文字列からSeverityMatcherを生成する
+// （例: type:aws_kms_key critical, attr:deletion_protection high, aws_instance.*.instance_type low）
+func NewSeverityMatcher(rules []string) *SeverityMatcher {
+	m := &SeverityMatcher{warnings: make([]string, 0)}
+
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		if len(fields) != 2 || types.SeverityRank(fields[1]) == 0 {
+			m.warnings = append(m.warnings, fmt.Sprintf("重要度ルール '%s' は「対象 重要度（info, low, medium, high, critical）」の形式で指定してください", rule))
+			continue
+		}
+
+		target, severity := fields[0], fields[1]
+		if resourceType, found := strings.CutPrefix(target, severityTargetType); found {
+			m.rules = append(m.rules, severityRule{resourceType: resourceType, severity: severity})
+		} else if attribute, found := strings.CutPrefix(target, severityTargetAttr); found {
+			m.rules = append(m.rules, severityRule{attribute: attribute, severity: severity})
+		} else {
+			m.rules = append(m.rules, severityRule{pattern: compileSeverityPattern(target), severity: severity})
+		}
+	}
+
+	return m
+}
+
+// compileSeverityPattern はパス指定を正規表現に変換する（無視ルールと同様に子パスにもマッチする）
+func compileSeverityPattern(target string) *regexp.Regexp {
+	pattern := strings.ReplaceAll(regexp.QuoteMeta(target), `\*`, `[^.]*`)
+	return regexp.MustCompile(`^` + pattern + `(\..*)?$`)
+}
+
+// HasRules は重要度ルールが1件以上あるかどうかを返す
+func (m *SeverityMatcher) HasRules() bool {
+	return len(m.rules) > 0
+}
+
+// Assign は差分に重要度を割り当てる（複数のルールにマッチした場合は最も高い重要度）
+func (m *SeverityMatcher) Assign(diffs []*types.DiffResult) {
+	for _, diff := range diffs {
+		diff.Severity = ""
+		for _, rule := range m.rules {
+			if rule.matches(diff) && types.SeverityRank(rule.severity) > types.SeverityRank(diff.Severity) {
+				diff.Severity = rule.severity
+			}
+		}
+	}
+}
+
+// GetWarnings は不正な重要度ルールの警告を返す
+func (m *SeverityMatcher) GetWarnings() []string {
+	return m.warnings
+}
+
+// matches は差分が重要度ルールの対象かどうかを判定する
+func (r severityRule) matches(diff *types.DiffResult) bool {
+	switch {
+	case r.resourceType != "":
+		return diffResourceType(diff) == r.resourceType
+	case r.attribute != "":
+		for _, segment := range strings.Split(diff.Path, ".") {
+			if stripIndex(segment) == r.attribute {
+				return true
+			}
+		}
+		return false
+	default:
+		return r.pattern.MatchString(diff.Address().String())
+	}
+}
+
+// diffResourceType は差分のアドレスからリソースタイプを取り出す
+// （module.app.aws_instance.web -> aws_instance, data.aws_ami.ubuntu -> aws_ami, local.x -> local）
+func diffResourceType(diff *types.DiffResult) string {
+	address := diff.Address()
+	if address.Type != "" {
+		return address.Type
+	}
+	return address.Kind
+}
+
+// stripIndex はパスの要素末尾のインデックス（[0]等）を取り除く
+func stripIndex(segment string) string {
+	if index := strings.Index(segment, "["); index != -1 {
+		return segment[:index]
+	}
+	return segment
+}
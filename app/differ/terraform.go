@@ -0,0 +1,171 @@
+package differ
+
+import (
+	"fmt"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// compareProviders はproviderブロック間の差分を比較する
+// プロバイダは provider.<名前>[<alias>] で識別する
+func (d *HCLDiffer) compareProviders(baseProviders, envProviders []*types.EnvProvider, env string) []*types.DiffResult {
+	var results []*types.DiffResult
+
+	baseProviderMap := make(map[string]*types.EnvProvider)
+	for _, provider := range baseProviders {
+		baseProviderMap[provider.Address().String()] = provider
+	}
+
+	envProviderMap := make(map[string]*types.EnvProvider)
+	for _, provider := range envProviders {
+		envProviderMap[provider.Address().String()] = provider
+	}
+
+	// 全プロバイダアドレスを収集
+	allAddresses := make(map[string]bool)
+	for address := range baseProviderMap {
+		allAddresses[address] = true
+	}
+	for address := range envProviderMap {
+		allAddresses[address] = true
+	}
+
+	for key := range allAddresses {
+		baseProvider, baseExists := baseProviderMap[key]
+		envProvider, envExists := envProviderMap[key]
+
+		if baseExists != envExists {
+			provider := baseProvider
+			if provider == nil {
+				provider = envProvider
+			}
+			results = append(results, d.newDiff(provider.Address(), env, cty.BoolVal(baseExists), cty.BoolVal(envExists)))
+			continue
+		}
+
+		// 属性（tagsのネストを含む）とネストブロック（assume_role, default_tags等）を比較
+		// aliasをインスタンスキーとして扱うため、provider.aws.region のようなエイリアスなしのルールで全エイリアスを無視できる
+		address := baseProvider.Address()
+		results = append(results, d.compareAttributes(address, providerAsResource(baseProvider), providerAsResource(envProvider), env)...)
+		results = append(results, d.compareBlocks(address, providerAsResource(baseProvider), providerAsResource(envProvider), env)...)
+	}
+
+	return results
+}
+
+// providerAsResource はEnvProviderをEnvResourceとして扱えるように変換する
+func providerAsResource(provider *types.EnvProvider) *types.EnvResource {
+	return &types.EnvResource{
+		Type:   "provider",
+		Name:   provider.Name,
+		Key:    provider.Alias,
+		Attrs:  provider.Attrs,
+		Blocks: provider.Blocks,
+	}
+}
+
+// compareTerraform はterraformブロック（required_version, required_providers, backend）の差分を比較する
+// 差分は terraform リソースの属性パスとして報告する（例: terraform.required_providers.aws.version）
+func (d *HCLDiffer) compareTerraform(baseTerraform, envTerraform *types.EnvTerraform, env string) []*types.DiffResult {
+	if baseTerraform == nil && envTerraform == nil {
+		return nil
+	}
+	baseTerraform = terraformOrEmpty(baseTerraform)
+	envTerraform = terraformOrEmpty(envTerraform)
+
+	// 直下の属性（required_version等）
+	results := d.compareTerraformAttributes(baseTerraform.Attrs, envTerraform.Attrs, "", env)
+
+	// required_providers
+	allProviderNames := make(map[string]bool)
+	for name := range baseTerraform.RequiredProviders {
+		allProviderNames[name] = true
+	}
+	for name := range envTerraform.RequiredProviders {
+		allProviderNames[name] = true
+	}
+
+	for name := range allProviderNames {
+		baseAttrs, baseExists := baseTerraform.RequiredProviders[name]
+		attrs, exists := envTerraform.RequiredProviders[name]
+		path := fmt.Sprintf("required_providers.%s", name)
+
+		if baseExists != exists {
+			results = append(results, d.newTerraformDiff(path, attrsAsValue(baseAttrs, baseExists), attrsAsValue(attrs, exists), env))
+			continue
+		}
+		results = append(results, d.compareTerraformAttributes(baseAttrs, attrs, path, env)...)
+	}
+
+	// backend（種類が異なる場合はbackend全体を1つの差分として報告）
+	baseBackend, envBackend := baseTerraform.Backend, envTerraform.Backend
+	switch {
+	case baseBackend == nil && envBackend == nil:
+	case baseBackend == nil || envBackend == nil || baseBackend.Type != envBackend.Type:
+		results = append(results, d.newTerraformDiff("backend", backendValue(baseBackend), backendValue(envBackend), env))
+	default:
+		path := fmt.Sprintf("backend.%s", baseBackend.Type)
+		results = append(results, d.compareTerraformAttributes(baseBackend.Attrs, envBackend.Attrs, path, env)...)
+	}
+
+	return results
+}
+
+// compareTerraformAttributes はterraformブロック配下の属性マップを比較する
+func (d *HCLDiffer) compareTerraformAttributes(baseAttrs, targetAttrs map[string]cty.Value, pathPrefix, env string) []*types.DiffResult {
+	callback := func(attrName string, baseValue, value cty.Value, baseExists, exists bool) *types.DiffResult {
+		if baseValue.Equals(value).True() {
+			return nil
+		}
+
+		path := attrName
+		if pathPrefix != "" {
+			path = fmt.Sprintf("%s.%s", pathPrefix, attrName)
+		}
+		return d.newTerraformDiff(path, baseValue, value, env)
+	}
+
+	return d.compareMapAttributes(baseAttrs, targetAttrs, callback)
+}
+
+// newTerraformDiff はterraformブロックの差分結果を生成する
+func (d *HCLDiffer) newTerraformDiff(path string, expected, actual cty.Value, env string) *types.DiffResult {
+	return d.newDiff(types.BlockAddress(types.KindTerraform, "").WithPath(path), env, expected, actual)
+}
+
+// terraformOrEmpty はnilの場合に空のEnvTerraformを返す
+func terraformOrEmpty(terraform *types.EnvTerraform) *types.EnvTerraform {
+	if terraform != nil {
+		return terraform
+	}
+	return &types.EnvTerraform{
+		Attrs:             map[string]cty.Value{},
+		RequiredProviders: map[string]map[string]cty.Value{},
+	}
+}
+
+// attrsAsValue は属性マップをオブジェクト値に変換する（存在しない場合はnull）
+func attrsAsValue(attrs map[string]cty.Value, exists bool) cty.Value {
+	if !exists {
+		return cty.NullVal(cty.DynamicPseudoType)
+	}
+	return cty.ObjectVal(attrs)
+}
+
+// backendValue はbackendを {<種類> = {<属性>}} の値として返す（backendがない場合はnull）
+// レポートで値を補填する際のterraform.backendのパス解決（types.EnvResource.Lookup）と同じ形にする
+func backendValue(backend *types.EnvBackend) cty.Value {
+	if backend == nil {
+		return cty.NullVal(cty.DynamicPseudoType)
+	}
+	return cty.ObjectVal(map[string]cty.Value{backend.Type: cty.ObjectVal(backend.Attrs)})
+}
+
+// backendTypeValue はbackendの種類を値として返す（backendがない場合はnull）
+func backendTypeValue(backend *types.EnvBackend) cty.Value {
+	if backend == nil {
+		return cty.NullVal(cty.String)
+	}
+	return cty.StringVal(backend.Type)
+}
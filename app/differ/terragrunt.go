@@ -0,0 +1,69 @@
+package differ
+
+import (
+	"fmt"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// compareTerragrunt はterragrunt.hcl（includeした親の構成を結合したもの）の差分を比較する
+// 差分は terragrunt リソースの属性パスとして報告する（例: terragrunt.inputs.instance_type, terragrunt.remote_state.bucket）
+func (d *HCLDiffer) compareTerragrunt(baseTerragrunt, envTerragrunt *types.EnvTerragrunt, env string) []*types.DiffResult {
+	if baseTerragrunt == nil && envTerragrunt == nil {
+		return nil
+	}
+	baseTerragrunt = terragruntOrEmpty(baseTerragrunt)
+	envTerragrunt = terragruntOrEmpty(envTerragrunt)
+
+	// トップレベルの属性（skip, iam_role等）・inputs・terraformブロック（source等）
+	results := d.compareTerragruntAttributes(baseTerragrunt.Attrs, envTerragrunt.Attrs, "", env)
+	results = append(results, d.compareTerragruntAttributes(baseTerragrunt.Inputs, envTerragrunt.Inputs, "inputs", env)...)
+	results = append(results, d.compareTerragruntAttributes(baseTerragrunt.TerraformAttrs, envTerragrunt.TerraformAttrs, "terraform", env)...)
+
+	// remote_state（backendが異なる場合はbackendの差分のみ報告）
+	baseRemoteState, envRemoteState := baseTerragrunt.RemoteState, envTerragrunt.RemoteState
+	switch {
+	case baseRemoteState == nil && envRemoteState == nil:
+	case baseRemoteState == nil || envRemoteState == nil || baseRemoteState.Type != envRemoteState.Type:
+		results = append(results, d.newTerragruntDiff("remote_state.backend", backendTypeValue(baseRemoteState), backendTypeValue(envRemoteState), env))
+	default:
+		results = append(results, d.compareTerragruntAttributes(baseRemoteState.Attrs, envRemoteState.Attrs, "remote_state", env)...)
+	}
+
+	return results
+}
+
+// compareTerragruntAttributes はterragrunt.hcl配下の属性マップを比較する
+func (d *HCLDiffer) compareTerragruntAttributes(baseAttrs, targetAttrs map[string]cty.Value, pathPrefix, env string) []*types.DiffResult {
+	callback := func(attrName string, baseValue, value cty.Value, baseExists, exists bool) *types.DiffResult {
+		if baseValue.Equals(value).True() {
+			return nil
+		}
+
+		path := attrName
+		if pathPrefix != "" {
+			path = fmt.Sprintf("%s.%s", pathPrefix, attrName)
+		}
+		return d.newTerragruntDiff(path, baseValue, value, env)
+	}
+
+	return d.compareMapAttributes(baseAttrs, targetAttrs, callback)
+}
+
+// newTerragruntDiff はterragrunt.hclの差分結果を生成する
+func (d *HCLDiffer) newTerragruntDiff(path string, expected, actual cty.Value, env string) *types.DiffResult {
+	return d.newDiff(types.BlockAddress(types.KindTerragrunt, "").WithPath(path), env, expected, actual)
+}
+
+// terragruntOrEmpty はnilの場合に空のEnvTerragruntを返す
+func terragruntOrEmpty(terragrunt *types.EnvTerragrunt) *types.EnvTerragrunt {
+	if terragrunt != nil {
+		return terragrunt
+	}
+	return &types.EnvTerragrunt{
+		Attrs:          map[string]cty.Value{},
+		Inputs:         map[string]cty.Value{},
+		TerraformAttrs: map[string]cty.Value{},
+	}
+}
@@ -0,0 +1,156 @@
+package git
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repository はローカルのgitリポジトリを表す（gitコマンドを使用し、ネットワークにはアクセスしない）
+type Repository struct {
+	Root string // リポジトリのルートディレクトリ
+}
+
+// OpenRepository は指定ディレクトリを含むgitリポジトリを開く
+func OpenRepository(dir string) (*Repository, error) {
+	output, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("gitリポジトリが見つかりません: %w\n"+
+			"ヒント: gitリポジトリ内で実行してください", err)
+	}
+
+	return &Repository{Root: strings.TrimSpace(string(output))}, nil
+}
+
+// ResolveRef はブランチ名・タグ・コミットハッシュ等をコミットハッシュに解決する
+func (r *Repository) ResolveRef(ref string) (string, error) {
+	output, err := runGit(r.Root, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("git ref '%s' が見つかりません\n"+
+			"ヒント: ブランチ名・タグ・コミットハッシュを指定してください（リモートのrefは事前にfetchしてください）", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ExportTree は指定コミット時点のリポジトリ全体をdestディレクトリに展開する
+func (r *Repository) ExportTree(ref, dest string) error {
+	commit, err := r.ResolveRef(ref)
+	if err != nil {
+		return err
+	}
+
+	// 大きなリポジトリでもアーカイブ全体をメモリに載せないよう、git archiveの出力を読みながら展開する
+	cmd := exec.Command("git", "archive", "--format=tar", commit)
+	cmd.Dir = r.Root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git ref '%s' のファイルを取得できませんでした: %w", ref, err)
+	}
+
+	if err := extractTar(stdout, dest); err != nil {
+		// 展開を中断した場合はgitが書き込みで止まらないよう終了させる
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+		return fmt.Errorf("git ref '%s' のファイルを取得できませんでした: %w", ref, err)
+	}
+	return nil
+}
+
+// RelativePath はパスをリポジトリルートからの相対パスに変換する
+func (r *Repository) RelativePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	// シンボリックリンク（/tmp等）を解決してから比較する
+	root, err := filepath.EvalSymlinks(r.Root)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+
+	relPath, err := filepath.Rel(root, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s はgitリポジトリ（%s）の外にあります", path, r.Root)
+	}
+	return relPath, nil
+}
+
+// runGit はgitコマンドを実行して標準出力を返す
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// extractTar はtarアーカイブの通常ファイルをdestディレクトリに展開する
+func extractTar(reader io.Reader, dest string) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("アーカイブの読み込みに失敗しました: %w", err)
+		}
+
+		// 展開先の外に書き込むパスは無視する
+		target := filepath.Join(dest, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(filepath.Separator)) {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, tarReader); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
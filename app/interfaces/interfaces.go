@@ -7,7 +7,8 @@ import (
 
 // ConfigServiceInterface は設定サービスのインターフェース
 type ConfigServiceInterface interface {
-	LoadConfig(envDirs []string, verbose, noFail bool, excludeDirs []string) (*config.Config, error)
+	LoadConfig(options *config.CheckOptions) (*config.Config, error)
+	LoadStacks(options *config.CheckOptions) (*config.StackConfig, error)
 }
 
 // AnalyzerServiceInterface は分析サービスのインターフェース
@@ -15,10 +16,23 @@ type AnalyzerServiceInterface interface {
 	Analyze(config *config.Config) (*AnalysisResult, error)
 }
 
+// HistoryServiceInterface は変更履歴サービスのインターフェース
+type HistoryServiceInterface interface {
+	Analyze(options *config.HistoryOptions) (*HistoryResult, error)
+}
+
 // OutputServiceInterface は出力サービスのインターフェース
 type OutputServiceInterface interface {
 	OutputResults(result *AnalysisResult, outputFile string, outputFlag bool, maxValueLength int, trimCell bool) error
+	OutputHistory(result *HistoryResult, outputFile string, outputFlag bool, maxValueLength int, trimCell bool) error
 	PrintSummary(diffs []*types.DiffResult) (int, int)
+	PrintPendingChanges(pendingChanges map[string][]*types.PendingChange) int
+	PrintBaselineSummary(result *AnalysisResult)
+	PrintDuplicates(duplicates []*types.DuplicateDefinition)
+	OutputStackResults(results []*StackResult, outputFile string, outputFlag bool, maxValueLength int, trimCell bool) error
+	PrintStackSummary(results []*StackResult) int
+	WriteResults(result *AnalysisResult, outputFile string, maxValueLength int, trimCell bool) error
+	PrintWatchSummary(previous, current []*types.DiffResult)
 }
 
 // ParserInterface はHCLパーサーのインターフェース
@@ -30,6 +44,8 @@ type ParserInterface interface {
 type DifferInterface interface {
 	Compare(envResources map[string]*types.EnvResources) ([]*types.DiffResult, error)
 	GetIgnoreWarnings() []string
+	GetStaleRules() []string
+	GetExpiredRules() []string
 }
 
 // ReporterInterface はレポート生成のインターフェース
@@ -43,4 +59,54 @@ type AnalysisResult struct {
 	EnvResources map[string]*types.EnvResources
 	RuleComments map[string]string
 	EnvNames     []string
+
+	PendingChanges map[string][]*types.PendingChange // 環境名 -> planに含まれる未適用の変更（plan比較時のみ）
+	BaseRef        string                            // --base-refで指定した基準ref
+	ResolvedDiffs  []*types.DiffResult               // 基準refにはあったが解消された差分（--base-ref指定時のみ）
+
+	BaselineFile         string                 // --baseline-fileで指定したベースラインファイル
+	BaselineDiffs        []*types.DiffResult    // ベースラインで許容された構成ドリフト
+	StaleBaselineEntries []*types.BaselineEntry // 現在は検出されなくなったベースラインの項目
+
+	StaleRules   []string // 実際のリソース構成に存在しない無視ルール
+	ExpiredRules []string // 期限切れの無視ルール
+
+	Groups []*GroupResult // 環境グループ内の比較結果（グループ定義時のみ。Diffsはグループ間の比較結果）
+
+	Duplicates []*types.DuplicateDefinition // 環境内で重複した定義（比較対象から除外したもの）
+}
+
+// AllDiffs はグループ間・グループ内の全ての差分を返す
+func (r *AnalysisResult) AllDiffs() []*types.DiffResult {
+	diffs := append([]*types.DiffResult{}, r.Diffs...)
+	for _, group := range r.Groups {
+		diffs = append(diffs, group.Diffs...)
+	}
+	return diffs
+}
+
+// GroupResult は環境グループ内の比較結果を表す
+type GroupResult struct {
+	Name         string
+	EnvNames     []string // グループ内の環境（1つ目が比較元）
+	Diffs        []*types.DiffResult
+	EnvResources map[string]*types.EnvResources
+	RuleComments map[string]string // グループの無視ルールのコメント
+	StaleRules   []string          // 実際のリソース構成に存在しないグループの無視ルール
+	ExpiredRules []string          // 期限切れのグループの無視ルール
+}
+
+// StackResult はスタックごとの分析結果を表す
+type StackResult struct {
+	Name   string
+	Result *AnalysisResult
+}
+
+// HistoryResult は単一環境の変更履歴の分析結果を表す
+type HistoryResult struct {
+	EnvName      string
+	Since        string
+	Diffs        []*types.DiffResult
+	Columns      []string                       // 比較する時点（指定ref, 現在）
+	EnvResources map[string]*types.EnvResources // 時点 -> 構成
 }
\ No newline at end of file
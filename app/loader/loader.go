@@ -0,0 +1,257 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// sensitiveValue は機密値を置き換える表示用の値
+const sensitiveValue = "(sensitive)"
+
+// JSONLoader は terraform show -json の出力をtypesのモデルに変換する
+type JSONLoader struct{}
+
+func NewJSONLoader() *JSONLoader {
+	return &JSONLoader{}
+}
+
+// jsonModule は state / plan の root_module・child_modules の共通構造
+type jsonModule struct {
+	Address      string          `json:"address"`
+	Resources    []*jsonResource `json:"resources"`
+	ChildModules []*jsonModule   `json:"child_modules"`
+}
+
+// jsonResource は state / plan のリソース値
+type jsonResource struct {
+	Address         string          `json:"address"`
+	Mode            string          `json:"mode"` // managed または data
+	Type            string          `json:"type"`
+	Name            string          `json:"name"`
+	Index           json.RawMessage `json:"index"`
+	Values          json.RawMessage `json:"values"`
+	SensitiveValues json.RawMessage `json:"sensitive_values"`
+}
+
+// jsonOutput は state / plan の出力値
+type jsonOutput struct {
+	Value     json.RawMessage `json:"value"`
+	Sensitive bool            `json:"sensitive"`
+}
+
+// readJSONFile はJSONファイルを読み込んで指定の構造体に変換する
+func readJSONFile(filename string, v any) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("JSONの解析に失敗しました: %w\n"+
+			"ヒント: terraform show -json の出力を指定してください", err)
+	}
+	return nil
+}
+
+// convertModule はモジュールのリソース・データソース・子モジュールをEnvResourcesに変換する
+func convertModule(module *jsonModule) (*types.EnvResources, error) {
+	envResources := &types.EnvResources{}
+	if module == nil {
+		return envResources, nil
+	}
+
+	for _, resource := range module.Resources {
+		key, err := instanceKey(resource.Index)
+		if err != nil {
+			return nil, fmt.Errorf("%s のインスタンスキーを解析できませんでした: %w", resource.Address, err)
+		}
+
+		attrs, err := convertValues(resource.Values, resource.SensitiveValues)
+		if err != nil {
+			return nil, fmt.Errorf("%s の属性値を解析できませんでした: %w", resource.Address, err)
+		}
+
+		if resource.Mode == "data" {
+			envResources.DataSources = append(envResources.DataSources, &types.EnvData{
+				Type:   resource.Type,
+				Name:   resource.Name,
+				Key:    key,
+				Attrs:  attrs,
+				Blocks: make(map[string][]*types.EnvBlock),
+			})
+			continue
+		}
+
+		envResources.Resources = append(envResources.Resources, &types.EnvResource{
+			Type:   resource.Type,
+			Name:   resource.Name,
+			Key:    key,
+			Attrs:  attrs,
+			Blocks: make(map[string][]*types.EnvBlock),
+		})
+	}
+
+	for _, childModule := range module.ChildModules {
+		children, err := convertModule(childModule)
+		if err != nil {
+			return nil, err
+		}
+
+		envResources.Modules = append(envResources.Modules, &types.EnvModule{
+			Name:     moduleName(childModule.Address),
+			Attrs:    make(map[string]cty.Value),
+			Children: children,
+		})
+	}
+
+	return envResources, nil
+}
+
+// convertOutputs は出力値をEnvOutputに変換する（機密値は置き換える）
+func convertOutputs(outputs map[string]*jsonOutput) ([]*types.EnvOutput, error) {
+	// 実行ごとに同じ順序で返すよう、名前の順に変換する
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var envOutputs []*types.EnvOutput
+	for _, name := range names {
+		output := outputs[name]
+		value := cty.StringVal(sensitiveValue)
+		if !output.Sensitive {
+			var err error
+			if value, err = jsonToValue(output.Value); err != nil {
+				return nil, fmt.Errorf("output.%s の値を解析できませんでした: %w", name, err)
+			}
+		}
+
+		envOutputs = append(envOutputs, &types.EnvOutput{
+			Name:  name,
+			Attrs: map[string]cty.Value{"value": value},
+		})
+	}
+	return envOutputs, nil
+}
+
+// convertValues はリソースの属性値を属性マップに変換する
+// sensitive_values で機密とされた値は置き換える
+func convertValues(values, sensitiveValues json.RawMessage) (map[string]cty.Value, error) {
+	var raw map[string]any
+	if len(values) > 0 {
+		if err := json.Unmarshal(values, &raw); err != nil {
+			return nil, err
+		}
+	}
+
+	var sensitive any
+	if len(sensitiveValues) > 0 {
+		if err := json.Unmarshal(sensitiveValues, &sensitive); err != nil {
+			return nil, err
+		}
+	}
+
+	attrs := make(map[string]cty.Value)
+	for name, value := range raw {
+		var sensitiveAttr any
+		if sensitiveMap, ok := sensitive.(map[string]any); ok {
+			sensitiveAttr = sensitiveMap[name]
+		}
+
+		converted, err := anyToValue(maskSensitive(value, sensitiveAttr))
+		if err != nil {
+			return nil, err
+		}
+		attrs[name] = converted
+	}
+	return attrs, nil
+}
+
+// maskSensitive は sensitive_values の構造に従って機密値を置き換える
+func maskSensitive(value, sensitive any) any {
+	switch s := sensitive.(type) {
+	case bool:
+		if s {
+			return sensitiveValue
+		}
+	case map[string]any:
+		if m, ok := value.(map[string]any); ok {
+			masked := make(map[string]any, len(m))
+			for k, v := range m {
+				masked[k] = maskSensitive(v, s[k])
+			}
+			return masked
+		}
+	case []any:
+		if l, ok := value.([]any); ok {
+			masked := make([]any, len(l))
+			for i, v := range l {
+				if i < len(s) {
+					masked[i] = maskSensitive(v, s[i])
+				} else {
+					masked[i] = v
+				}
+			}
+			return masked
+		}
+	}
+	return value
+}
+
+// anyToValue はJSONから読み込んだ値をcty.Valueに変換する
+func anyToValue(value any) (cty.Value, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return jsonToValue(content)
+}
+
+// jsonToValue はJSONの値を型を推論してcty.Valueに変換する
+func jsonToValue(content json.RawMessage) (cty.Value, error) {
+	if len(content) == 0 {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+
+	valueType, err := ctyjson.ImpliedType(content)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(content, valueType)
+}
+
+// instanceKey はリソースのindex（countの番号、for_eachのキー）をインスタンスキーに変換する
+func instanceKey(index json.RawMessage) (string, error) {
+	if len(index) == 0 || string(index) == "null" {
+		return "", nil
+	}
+
+	var key any
+	if err := json.Unmarshal(index, &key); err != nil {
+		return "", err
+	}
+
+	switch k := key.(type) {
+	case float64:
+		return strconv.FormatFloat(k, 'f', -1, 64), nil
+	case string:
+		return strconv.Quote(k), nil
+	default:
+		return "", fmt.Errorf("サポートされていないインスタンスキーです: %s", string(index))
+	}
+}
+
+// moduleName はモジュールアドレス（module.app.module.db[0]）から最後のモジュール名（db[0]）を取り出す
+func moduleName(address string) string {
+	if parsed, err := types.ParseAddress(address); err == nil && parsed.Kind == types.KindModule {
+		return parsed.Name
+	}
+	return address
+}
@@ -0,0 +1,149 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// unknownValue は適用するまで値が確定しない属性を置き換える表示用の値
+const unknownValue = "(known after apply)"
+
+// jsonPlan は terraform show -json（planファイル）の出力
+type jsonPlan struct {
+	FormatVersion string `json:"format_version"`
+	Variables     map[string]*struct {
+		Value json.RawMessage `json:"value"`
+	} `json:"variables"`
+	PlannedValues *struct {
+		Outputs    map[string]*jsonOutput `json:"outputs"`
+		RootModule *jsonModule            `json:"root_module"`
+	} `json:"planned_values"`
+	ResourceChanges []*jsonResourceChange `json:"resource_changes"`
+	Configuration   struct {
+		RootModule struct {
+			Variables map[string]*struct {
+				Sensitive bool `json:"sensitive"`
+			} `json:"variables"`
+		} `json:"root_module"`
+	} `json:"configuration"`
+}
+
+// jsonResourceChange は resource_changes の1エントリ
+type jsonResourceChange struct {
+	Address string `json:"address"`
+	Change  struct {
+		Actions      []string        `json:"actions"`
+		AfterUnknown json.RawMessage `json:"after_unknown"`
+	} `json:"change"`
+}
+
+// LoadPlan は terraform show -json で出力したplanを読み込み、適用後の構成をEnvResourcesに変換する
+// 変数・count/for_each・モジュールの展開はTerraformが解決した結果（planned_values）を使用し、
+// resource_changes から適用後に確定する属性と未適用の変更を取り出す
+func (l *JSONLoader) LoadPlan(filename string) (*types.EnvResources, []*types.PendingChange, error) {
+	var plan jsonPlan
+	if err := readJSONFile(filename, &plan); err != nil {
+		return nil, nil, err
+	}
+	if plan.FormatVersion == "" || plan.PlannedValues == nil {
+		return nil, nil, fmt.Errorf("planのJSONではありません（format_version / planned_valuesがありません）\n" +
+			"ヒント: terraform show -json plan.out の出力を指定してください")
+	}
+
+	envResources, err := convertModule(plan.PlannedValues.RootModule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	envResources.Outputs, err = convertOutputs(plan.PlannedValues.Outputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 実行ごとに同じ順序で返すよう、名前の順に変換する
+	variableNames := make([]string, 0, len(plan.Variables))
+	for name := range plan.Variables {
+		variableNames = append(variableNames, name)
+	}
+	sort.Strings(variableNames)
+
+	for _, name := range variableNames {
+		variable := plan.Variables[name]
+		value := cty.StringVal(sensitiveValue)
+		if config, exists := plan.Configuration.RootModule.Variables[name]; !exists || !config.Sensitive {
+			if value, err = jsonToValue(variable.Value); err != nil {
+				return nil, nil, fmt.Errorf("var.%s の値を解析できませんでした: %w", name, err)
+			}
+		}
+		envResources.Variables = append(envResources.Variables, &types.EnvVariable{
+			Name:  name,
+			Attrs: map[string]cty.Value{"value": value},
+		})
+	}
+
+	var pendingChanges []*types.PendingChange
+	for _, resourceChange := range plan.ResourceChanges {
+		if err := markUnknownAttrs(envResources, resourceChange); err != nil {
+			return nil, nil, err
+		}
+		if isPendingChange(resourceChange.Change.Actions) {
+			pendingChanges = append(pendingChanges, &types.PendingChange{
+				Address: resourceChange.Address,
+				Actions: resourceChange.Change.Actions,
+			})
+		}
+	}
+
+	return envResources, pendingChanges, nil
+}
+
+// markUnknownAttrs は適用後に確定する属性（after_unknownでtrueのもの）をplanned_valuesのリソースに追加する
+// planned_valuesでは値が省略されるため、環境間で存在差分として扱われないよう表示用の値で埋める
+func markUnknownAttrs(envResources *types.EnvResources, resourceChange *jsonResourceChange) error {
+	if len(resourceChange.Change.AfterUnknown) == 0 {
+		return nil
+	}
+
+	var afterUnknown map[string]any
+	if err := json.Unmarshal(resourceChange.Change.AfterUnknown, &afterUnknown); err != nil {
+		return fmt.Errorf("%s のafter_unknownを解析できませんでした: %w", resourceChange.Address, err)
+	}
+
+	attrs := findAttrs(envResources, resourceChange.Address)
+	if attrs == nil {
+		return nil
+	}
+
+	for name, unknown := range afterUnknown {
+		if isUnknown, ok := unknown.(bool); ok && isUnknown {
+			attrs[name] = cty.StringVal(unknownValue)
+		}
+	}
+	return nil
+}
+
+// findAttrs はアドレス（module.app.aws_instance.web[0]）に対応するリソース・データソースの属性マップを返す
+func findAttrs(envResources *types.EnvResources, address string) map[string]cty.Value {
+	parsed, err := types.ParseAddress(address)
+	if err != nil || (parsed.Kind != types.KindResource && parsed.Kind != types.KindData) {
+		return nil
+	}
+	if block := envResources.Lookup(parsed); block != nil {
+		return block.Attrs
+	}
+	return nil
+}
+
+// isPendingChange は変更が未適用の変更（no-op・read以外）かどうかを判定する
+func isPendingChange(actions []string) bool {
+	for _, action := range actions {
+		if action != "no-op" && action != "read" {
+			return true
+		}
+	}
+	return false
+}
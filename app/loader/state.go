@@ -0,0 +1,45 @@
+package loader
+
+import (
+	"fmt"
+
+	"github.com/Mkamono/tfspec/app/types"
+)
+
+// jsonState は terraform show -json（stateファイル）の出力
+type jsonState struct {
+	FormatVersion string `json:"format_version"`
+	Values        *struct {
+		Outputs    map[string]*jsonOutput `json:"outputs"`
+		RootModule *jsonModule            `json:"root_module"`
+	} `json:"values"`
+}
+
+// LoadState は terraform show -json で出力したstateを読み込み、実際にデプロイされている属性値をEnvResourcesに変換する
+func (l *JSONLoader) LoadState(filename string) (*types.EnvResources, error) {
+	var state jsonState
+	if err := readJSONFile(filename, &state); err != nil {
+		return nil, err
+	}
+	if state.FormatVersion == "" {
+		return nil, fmt.Errorf("stateのJSONではありません（format_versionがありません）\n" +
+			"ヒント: terraform show -json の出力を指定してください")
+	}
+
+	// リソースが1つもないstateはvaluesを持たない
+	if state.Values == nil {
+		return &types.EnvResources{}, nil
+	}
+
+	envResources, err := convertModule(state.Values.RootModule)
+	if err != nil {
+		return nil, err
+	}
+
+	envResources.Outputs, err = convertOutputs(state.Values.Outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return envResources, nil
+}
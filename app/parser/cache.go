@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// cachedFile はトップレベルのファイルごとにキャッシュする内容
+// メタ引数評価用コンテキストの材料（variable・locals）も保存し、変更のないファイルは構文解析せずに済ませる
+type cachedFile struct {
+	Variables  map[string]cty.Value   // variableのdefault値（評価できるもの）
+	Locals     map[string]cachedLocal // locals名 -> 属性のソーステキスト
+	ContextKey string                 // 解析時のメタ引数評価用コンテキスト（var/local）のハッシュ
+	Resources  *types.EnvResources    // ファイルの解析結果
+}
+
+// cachedLocal はlocalsの属性のソーステキスト（name = 式）とファイル内の開始位置
+// 評価エラーが元のファイルの行を指すよう、開始位置から構文解析し直す
+type cachedLocal struct {
+	Source string
+	Start  hcl.Pos
+}
+
+// parseFilesWithCache はキャッシュを使ってトップレベルのファイルを解析し、ファイルごとの解析結果を順番に返す
+// ファイルの解析結果は内容・パス（ローカルモジュールの解決と定義位置に使用）・他ファイルを含めたvar/localの値で決まるため、
+// 内容とパスをキーに保存し、var/localの値が解析時と同じ場合のみ再利用する
+func (p *HCLParser) parseFilesWithCache(filenames []string, varValues map[string]cty.Value) ([]*types.EnvResources, error) {
+	keys := make([]string, len(filenames))
+	entries := make([]*cachedFile, len(filenames))
+	vars := make(map[string]cty.Value)
+	localExprs := make(map[string]hcl.Expression)
+
+	for i, filename := range filenames {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			_, diags := p.files.parse(filename)
+			return nil, diags
+		}
+		path, err := filepath.Abs(filename)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = p.cache.Key(src, []byte(path))
+
+		entry := &cachedFile{}
+		if !p.cache.Load(keys[i], entry) {
+			file, diags := p.files.parse(filename)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			entry = newCachedFile(file)
+		}
+		entries[i] = entry
+
+		// 同じ名前は後のファイルで上書きする（collectMetaInputsと同じ）
+		for name, value := range entry.Variables {
+			vars[name] = value
+		}
+		for name, local := range entry.Locals {
+			if expr, ok := parseLocalSource(name, local, filename); ok {
+				localExprs[name] = expr
+			}
+		}
+	}
+
+	metaCtx := newMetaEvalContext(vars, localExprs, varValues)
+	contextKey, cacheable := p.evalContextKey(metaCtx)
+
+	fragments := make([]*types.EnvResources, len(filenames))
+	for i, filename := range filenames {
+		entry := entries[i]
+		if cacheable && entry.Resources != nil && entry.ContextKey == contextKey {
+			p.cache.Record(true)
+			fragments[i] = entry.Resources
+			continue
+		}
+		p.cache.Record(false)
+
+		file, diags := p.files.parse(filename)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		// ファイルごとに依存するモジュールを記録し直す（同じ実行で先に他のファイルが読み込んだモジュールも
+		// 改めて記録し、共有するモジュールの変更で全ての参照元ファイルのキャッシュが無効になるようにする）
+		p.moduleDirs = nil
+		envResources, err := p.parseFile(filename, file, metaCtx, &hcl.EvalContext{})
+		if err != nil {
+			return nil, err
+		}
+		fragments[i] = envResources
+
+		// キャッシュは高速化のためのものなので、保存できなくても解析は続ける
+		if cacheable {
+			entry.ContextKey = contextKey
+			entry.Resources = envResources
+			_ = p.cache.Save(keys[i], entry, p.moduleDirs)
+		}
+	}
+	return fragments, nil
+}
+
+// newCachedFile は構文解析済みのファイルからメタ引数評価用コンテキストの材料を取り出す
+func newCachedFile(file *hcl.File) *cachedFile {
+	entry := &cachedFile{
+		Variables: make(map[string]cty.Value),
+		Locals:    make(map[string]cachedLocal),
+	}
+	localAttrs := make(map[string]*hclsyntax.Attribute)
+	collectMetaInputs(file, entry.Variables, localAttrs)
+	for name, attr := range localAttrs {
+		entry.Locals[name] = cachedLocal{
+			Source: string(attr.SrcRange.SliceBytes(file.Bytes)),
+			Start:  attr.SrcRange.Start,
+		}
+	}
+	return entry
+}
+
+// parseLocalSource はキャッシュしたlocalsの属性のソーステキストから式を取り出す
+func parseLocalSource(name string, local cachedLocal, filename string) (hcl.Expression, bool) {
+	file, diags := hclsyntax.ParseConfig([]byte(local.Source+"\n"), filename, local.Start)
+	if diags.HasErrors() {
+		return nil, false
+	}
+	attr, exists := file.Body.(*hclsyntax.Body).Attributes[name]
+	if !exists {
+		return nil, false
+	}
+	return attr.Expr, true
+}
+
+// evalContextKey はメタ引数評価用コンテキストのvar/localの値のハッシュを求める
+// 値を変換できない場合は解析結果をキャッシュしない（falseを返す）
+func (p *HCLParser) evalContextKey(ctx *hcl.EvalContext) (string, bool) {
+	var parts [][]byte
+	for _, name := range []string{"var", "local"} {
+		data, err := ctyjson.Marshal(ctx.Variables[name], cty.DynamicPseudoType)
+		if err != nil {
+			return "", false
+		}
+		parts = append(parts, data)
+	}
+	return p.cache.Key(parts...), true
+}
@@ -0,0 +1,382 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// resourceInstance は count / for_each で展開された1インスタンスの評価情報
+type resourceInstance struct {
+	key     string
+	evalCtx *hcl.EvalContext
+}
+
+// metaFunctions はメタ引数の評価で利用できる関数群（Terraform組み込み関数のうち副作用のないもの）
+func metaFunctions() map[string]function.Function {
+	return map[string]function.Function{
+		"concat":   stdlib.ConcatFunc,
+		"contains": stdlib.ContainsFunc,
+		"distinct": stdlib.DistinctFunc,
+		"element":  stdlib.ElementFunc,
+		"flatten":  stdlib.FlattenFunc,
+		"format":   stdlib.FormatFunc,
+		"join":     stdlib.JoinFunc,
+		"keys":     stdlib.KeysFunc,
+		"length":   stdlib.LengthFunc,
+		"lookup":   stdlib.LookupFunc,
+		"lower":    stdlib.LowerFunc,
+		"max":      stdlib.MaxFunc,
+		"merge":    stdlib.MergeFunc,
+		"min":      stdlib.MinFunc,
+		"range":    stdlib.RangeFunc,
+		"split":    stdlib.SplitFunc,
+		"tolist":   stdlib.MakeToFunc(cty.List(cty.DynamicPseudoType)),
+		"tomap":    stdlib.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+		"tonumber": stdlib.MakeToFunc(cty.Number),
+		"toset":    stdlib.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
+		"tostring": stdlib.MakeToFunc(cty.String),
+		"upper":    stdlib.UpperFunc,
+		"values":   stdlib.ValuesFunc,
+		"zipmap":   stdlib.ZipmapFunc,
+	}
+}
+
+// buildMetaEvalContext は count / for_each 評価用のコンテキストを構築する
+// variableのdefault値（varValuesで上書き）をvar、評価可能なlocalsをlocalとして登録する
+func (p *HCLParser) buildMetaEvalContext(files map[string]*hcl.File, filenames []string, varValues map[string]cty.Value) *hcl.EvalContext {
+	vars := make(map[string]cty.Value)
+	localAttrs := make(map[string]*hclsyntax.Attribute)
+	for _, filename := range filenames {
+		collectMetaInputs(files[filename], vars, localAttrs)
+	}
+
+	localExprs := make(map[string]hcl.Expression, len(localAttrs))
+	for name, attr := range localAttrs {
+		localExprs[name] = attr.Expr
+	}
+	return newMetaEvalContext(vars, localExprs, varValues)
+}
+
+// collectMetaInputs はファイルのvariableのdefault値（評価できるもの）とlocalsの属性を取り出す
+// 複数のファイルで同じ名前が定義されている場合は後のファイルで上書きする
+func collectMetaInputs(file *hcl.File, vars map[string]cty.Value, localAttrs map[string]*hclsyntax.Attribute) {
+	syntaxBody, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return
+	}
+
+	funcCtx := &hcl.EvalContext{Functions: metaFunctions()}
+	for _, block := range syntaxBody.Blocks {
+		switch block.Type {
+		case "variable":
+			if len(block.Labels) != 1 {
+				continue
+			}
+			if attr, exists := block.Body.Attributes["default"]; exists {
+				if value, diags := attr.Expr.Value(funcCtx); !diags.HasErrors() && value.IsWhollyKnown() {
+					vars[block.Labels[0]] = value
+				}
+			}
+		case "locals":
+			for name, attr := range block.Body.Attributes {
+				localAttrs[name] = attr
+			}
+		}
+	}
+}
+
+// newMetaEvalContext はvariableのdefault値とlocalsの式からメタ引数評価用のコンテキストを構築する
+func newMetaEvalContext(vars map[string]cty.Value, localExprs map[string]hcl.Expression, varValues map[string]cty.Value) *hcl.EvalContext {
+	// tfvars・モジュール入力の値でdefaultを上書き
+	for name, value := range varValues {
+		vars[name] = value
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.ObjectVal(vars),
+			"local": cty.EmptyObjectVal,
+		},
+		Functions: metaFunctions(),
+	}
+
+	evaluateLocals(ctx, localExprs)
+
+	return ctx
+}
+
+// evaluateLocals は評価可能なlocalsをコンテキストのlocalとして登録する
+// localsは相互参照があるため、評価できなくなるまで繰り返し評価する
+func evaluateLocals(ctx *hcl.EvalContext, localExprs map[string]hcl.Expression) {
+	locals := make(map[string]cty.Value)
+	for progress := true; progress; {
+		progress = false
+		for name, expr := range localExprs {
+			value, diags := expr.Value(ctx)
+			if diags.HasErrors() || !value.IsWhollyKnown() {
+				continue
+			}
+			locals[name] = value
+			delete(localExprs, name)
+			ctx.Variables["local"] = cty.ObjectVal(locals)
+			progress = true
+		}
+	}
+}
+
+// loadTfvars は解析対象ファイルと同じディレクトリの terraform.tfvars / *.auto.tfvars を読み込む
+func (p *HCLParser) loadTfvars(filenames []string, evalCtx *hcl.EvalContext) map[string]cty.Value {
+	values := make(map[string]cty.Value)
+
+	dirs := make(map[string]bool)
+	var dirList []string
+	for _, filename := range filenames {
+		dir := filepath.Dir(filename)
+		if !dirs[dir] {
+			dirs[dir] = true
+			dirList = append(dirList, dir)
+		}
+	}
+
+	for _, dir := range dirList {
+		var tfvarsFiles []string
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".auto.tfvars") {
+					tfvarsFiles = append(tfvarsFiles, filepath.Join(dir, entry.Name()))
+				}
+			}
+		}
+		// Terraformと同様に terraform.tfvars → *.auto.tfvars（辞書順）の順で適用
+		sort.Strings(tfvarsFiles)
+		tfvarsFiles = append([]string{filepath.Join(dir, "terraform.tfvars")}, tfvarsFiles...)
+
+		for _, tfvarsFile := range tfvarsFiles {
+			if _, err := os.Stat(tfvarsFile); err != nil {
+				continue
+			}
+			file, diags := p.files.parse(tfvarsFile)
+			if diags.HasErrors() {
+				continue
+			}
+			attrs, diags := file.Body.JustAttributes()
+			if diags.HasErrors() {
+				continue
+			}
+			for name, attr := range attrs {
+				if value, diags := attr.Expr.Value(evalCtx); !diags.HasErrors() && value.IsWhollyKnown() {
+					values[name] = value
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+// expandInstances は count / for_each を評価してインスタンス一覧を返す
+// メタ引数がない、または評価できない場合は expanded=false を返す（単一ブロックとして扱う）
+func (p *HCLParser) expandInstances(body hcl.Body, metaCtx *hcl.EvalContext) (instances []resourceInstance, expanded bool) {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return nil, false
+	}
+
+	if attr, exists := syntaxBody.Attributes["count"]; exists {
+		return expandCount(attr.Expr, metaCtx)
+	}
+	if attr, exists := syntaxBody.Attributes["for_each"]; exists {
+		return expandForEach(attr.Expr, metaCtx)
+	}
+	return nil, false
+}
+
+// expandCount は count の値からインスタンスを生成する
+func expandCount(expr hcl.Expression, metaCtx *hcl.EvalContext) ([]resourceInstance, bool) {
+	value, diags := expr.Value(metaCtx)
+	if diags.HasErrors() || value.IsNull() || !value.IsKnown() || value.Type() != cty.Number {
+		return nil, false
+	}
+
+	count, accuracy := value.AsBigFloat().Int64()
+	if accuracy != 0 || count < 0 {
+		return nil, false
+	}
+
+	instances := make([]resourceInstance, 0, count)
+	for i := range count {
+		instances = append(instances, resourceInstance{
+			key: strconv.FormatInt(i, 10),
+			evalCtx: &hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"count": cty.ObjectVal(map[string]cty.Value{
+						"index": cty.NumberIntVal(i),
+					}),
+				},
+			},
+		})
+	}
+	return instances, true
+}
+
+// expandForEach は for_each の値（map/object または文字列のset）からインスタンスを生成する
+func expandForEach(expr hcl.Expression, metaCtx *hcl.EvalContext) ([]resourceInstance, bool) {
+	value, diags := expr.Value(metaCtx)
+	if diags.HasErrors() || value.IsNull() || !value.IsWhollyKnown() {
+		return nil, false
+	}
+
+	valueType := value.Type()
+	isMap := valueType.IsMapType() || valueType.IsObjectType()
+	isStringSet := valueType.IsSetType() && valueType.ElementType() == cty.String
+	if !isMap && !isStringSet {
+		return nil, false
+	}
+
+	var instances []resourceInstance
+	for it := value.ElementIterator(); it.Next(); {
+		key, element := it.Element()
+		if isStringSet {
+			key = element
+		}
+
+		instances = append(instances, resourceInstance{
+			key: strconv.Quote(key.AsString()),
+			evalCtx: &hcl.EvalContext{
+				Variables: map[string]cty.Value{
+					"each": cty.ObjectVal(map[string]cty.Value{
+						"key":   key,
+						"value": element,
+					}),
+				},
+			},
+		})
+	}
+	return instances, true
+}
+
+// parseResourceInstances はresource/dataブロックを解析する
+// count / for_each が評価できる場合はインスタンスごとに展開し、メタ引数自体は属性から除外する
+func (p *HCLParser) parseResourceInstances(block *hcl.Block, filename string, metaCtx, attrCtx *hcl.EvalContext) ([]*types.EnvResource, error) {
+	instances, expanded := p.expandInstances(block.Body, metaCtx)
+	if !expanded {
+		instances = []resourceInstance{{evalCtx: &hcl.EvalContext{}}}
+	}
+
+	resources := make([]*types.EnvResource, 0, len(instances))
+	for _, instance := range instances {
+		envResource := &types.EnvResource{
+			Type:     block.Labels[0],
+			Name:     block.Labels[1],
+			Key:      instance.key,
+			Attrs:    make(map[string]cty.Value),
+			Blocks:   make(map[string][]*types.EnvBlock),
+			Location: sourceLocation(block.DefRange),
+		}
+
+		// 属性・dynamicブロックの for_each ではインスタンスの count / each も参照できる
+		instanceCtx := attrCtx.NewChild()
+		instanceCtx.Variables = instance.evalCtx.Variables
+		instanceMetaCtx := metaCtx.NewChild()
+		instanceMetaCtx.Variables = instance.evalCtx.Variables
+
+		if err := p.parseResourceContent(block.Body, filename, instanceCtx, instanceMetaCtx, envResource); err != nil {
+			return nil, err
+		}
+
+		if expanded {
+			delete(envResource.Attrs, "count")
+			delete(envResource.Attrs, "for_each")
+		}
+
+		resources = append(resources, envResource)
+	}
+
+	return resources, nil
+}
+
+// parseDynamicBlock はdynamicブロックを解析する
+// for_each が評価できる場合は content を要素ごとに評価して具体的なネストブロックを生成し、
+// 評価できない場合はテンプレートを "dynamic.<ブロック型>" として格納する
+// content 内のネストブロック・dynamicブロックも展開し、生成したブロックのネストブロックとする
+func (p *HCLParser) parseDynamicBlock(block *hclsyntax.Block, filename string, evalCtx, metaCtx *hcl.EvalContext, blocks map[string][]*types.EnvBlock) error {
+	blockType := block.Labels[0]
+
+	// イテレータ名（省略時はブロック型名）
+	iteratorName := blockType
+	if attr, exists := block.Body.Attributes["iterator"]; exists {
+		if keyword := hcl.ExprAsKeyword(attr.Expr); keyword != "" {
+			iteratorName = keyword
+		}
+	}
+
+	var contentBody *hclsyntax.Body
+	for _, nested := range block.Body.Blocks {
+		if nested.Type == "content" {
+			contentBody = nested.Body
+			break
+		}
+	}
+	if contentBody == nil {
+		return nil // contentのないdynamicブロックは生成されるブロックがない
+	}
+
+	if attr, exists := block.Body.Attributes["for_each"]; exists {
+		if collection, diags := attr.Expr.Value(metaCtx); !diags.HasErrors() && !collection.IsNull() && collection.IsWhollyKnown() && collection.CanIterateElements() {
+			for it := collection.ElementIterator(); it.Next(); {
+				key, element := it.Element()
+
+				iterator := map[string]cty.Value{
+					iteratorName: cty.ObjectVal(map[string]cty.Value{
+						"key":   key,
+						"value": element,
+					}),
+				}
+				contentCtx := evalCtx.NewChild()
+				contentCtx.Variables = iterator
+				// content 内のdynamicブロックの for_each でも外側のイテレータを参照できる
+				contentMetaCtx := metaCtx.NewChild()
+				contentMetaCtx.Variables = iterator
+
+				envBlock := &types.EnvBlock{
+					Type:   blockType,
+					Attrs:  make(map[string]cty.Value),
+					Blocks: make(map[string][]*types.EnvBlock),
+				}
+				if err := p.parseBlockContent(contentBody, filename, contentCtx, contentMetaCtx, envBlock.Attrs, envBlock.Blocks); err != nil {
+					return err
+				}
+				blocks[blockType] = append(blocks[blockType], envBlock)
+			}
+			return nil
+		}
+	}
+
+	// 展開できない場合はテンプレート（content の属性と for_each の式）を比較対象とする
+	templateType := "dynamic." + blockType
+	envBlock := &types.EnvBlock{
+		Type:   templateType,
+		Attrs:  make(map[string]cty.Value),
+		Blocks: make(map[string][]*types.EnvBlock),
+	}
+	if err := p.parseBlockContent(contentBody, filename, evalCtx, metaCtx, envBlock.Attrs, envBlock.Blocks); err != nil {
+		return err
+	}
+	if attr, exists := block.Body.Attributes["for_each"]; exists {
+		exprRange := attr.Expr.Range()
+		envBlock.Attrs["for_each"] = cty.StringVal(string(exprRange.SliceBytes(p.files.source(filename))))
+	}
+	blocks[templateType] = append(blocks[templateType], envBlock)
+
+	return nil
+}
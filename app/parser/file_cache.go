@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// fileCache は構文解析済みのHCLファイルのキャッシュ
+// 複数の環境を並行して解析する際に共有するため排他制御を行う（構文解析自体はロックの外で行う）
+type fileCache struct {
+	mu    sync.RWMutex
+	files map[string]*cachedSyntax
+}
+
+// cachedSyntax は構文解析済みのファイルと解析時の更新日時・サイズ
+// watchモードで同じパーサーを使い続けても、変更されたファイルは解析し直す
+type cachedSyntax struct {
+	file    *hcl.File
+	modTime time.Time
+	size    int64
+}
+
+func newFileCache() *fileCache {
+	return &fileCache{
+		files: make(map[string]*cachedSyntax),
+	}
+}
+
+// parse はファイルを構文解析する（解析後に変更されていない場合はキャッシュを返す）
+func (c *fileCache) parse(filename string) (*hcl.File, hcl.Diagnostics) {
+	info, statErr := os.Stat(filename)
+
+	c.mu.RLock()
+	cached, exists := c.files[filename]
+	c.mu.RUnlock()
+	if exists && statErr == nil && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.file, nil
+	}
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, hcl.Diagnostics{
+			{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to read file",
+				Detail:   fmt.Sprintf("The configuration file %q could not be read.", filename),
+			},
+		}
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Byte: 0, Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return file, diags
+	}
+
+	// 同じファイルを並行して解析した場合は先に登録されたものを使う
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing, exists := c.files[filename]; exists && existing != cached {
+		return existing.file, diags
+	}
+	entry := &cachedSyntax{file: file}
+	if statErr == nil {
+		entry.modTime = info.ModTime()
+		entry.size = info.Size()
+	}
+	c.files[filename] = entry
+	return file, diags
+}
+
+// source はファイルのソースバイト列を返す（Range.SliceBytes用）
+func (c *fileCache) source(filename string) []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if cached, exists := c.files[filename]; exists {
+		return cached.file.Bytes
+	}
+	return nil
+}
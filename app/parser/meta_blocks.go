@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// takeAddressAttr はmoved/import/removedブロックのアドレス属性（from/to）を取り出し、属性マップから除外する
+// アドレスは評価できない参照式のため、ソーステキストとして格納されている
+func takeAddressAttr(attrs map[string]cty.Value, name string) string {
+	value, exists := attrs[name]
+	if !exists {
+		return ""
+	}
+	delete(attrs, name)
+
+	if value.IsNull() || !value.IsKnown() || value.Type() != cty.String {
+		return ""
+	}
+	return value.AsString()
+}
+
+// parseRemovedBlock はremovedブロック（from属性とlifecycle等のネストブロック）を解析する
+func (p *HCLParser) parseRemovedBlock(block *hcl.Block, filename string, evalCtx, metaCtx *hcl.EvalContext) (*types.EnvRemoved, error) {
+	resource := &types.EnvResource{
+		Type:   "removed",
+		Attrs:  make(map[string]cty.Value),
+		Blocks: make(map[string][]*types.EnvBlock),
+	}
+	if err := p.parseResourceContent(block.Body, filename, evalCtx, metaCtx, resource); err != nil {
+		return nil, err
+	}
+
+	return &types.EnvRemoved{
+		From:   takeAddressAttr(resource.Attrs, "from"),
+		Attrs:  resource.Attrs,
+		Blocks: resource.Blocks,
+	}, nil
+}
+
+// parseCheckBlock はcheckブロック（assertブロックとスコープ付きdataブロック）を解析する
+func (p *HCLParser) parseCheckBlock(block *hcl.Block, filename string, evalCtx, metaCtx *hcl.EvalContext) (*types.EnvCheck, error) {
+	resource := &types.EnvResource{
+		Type:   "check",
+		Name:   block.Labels[0],
+		Attrs:  make(map[string]cty.Value),
+		Blocks: make(map[string][]*types.EnvBlock),
+	}
+	if err := p.parseResourceContent(block.Body, filename, evalCtx, metaCtx, resource); err != nil {
+		return nil, err
+	}
+
+	return &types.EnvCheck{
+		Name:   resource.Name,
+		Attrs:  resource.Attrs,
+		Blocks: resource.Blocks,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// maxModuleDepth はローカルモジュールを再帰的に解析する最大の深さ
+const maxModuleDepth = 10
+
+// moduleMetaArguments はモジュールの入力値として扱わないmoduleブロックの引数
+var moduleMetaArguments = map[string]bool{
+	"source":     true,
+	"version":    true,
+	"providers":  true,
+	"count":      true,
+	"for_each":   true,
+	"depends_on": true,
+}
+
+// parseLocalModule はローカルパスのモジュールを呼び出し元の入力値で具体化して解析する
+// ローカル以外のsource、ディレクトリが存在しない場合、循環参照・深さ制限に達した場合はnilを返す
+func (p *HCLParser) parseLocalModule(block *hcl.Block, filename string, metaCtx *hcl.EvalContext, attrs map[string]cty.Value) (*types.EnvResources, error) {
+	sourceValue, exists := attrs["source"]
+	if !exists || sourceValue.IsNull() || !sourceValue.IsKnown() || sourceValue.Type() != cty.String {
+		return nil, nil
+	}
+	source := sourceValue.AsString()
+	if !types.IsLocalModuleSource(source) {
+		return nil, nil
+	}
+
+	moduleDir, err := filepath.Abs(filepath.Join(filepath.Dir(filename), source))
+	if err != nil {
+		return nil, nil
+	}
+	// 解析しない場合（循環参照・ディレクトリがない等）も、後から変更・作成されたことを検出できるよう記録する
+	p.moduleDirs = append(p.moduleDirs, moduleDir)
+	if p.moduleStack[moduleDir] || len(p.moduleStack) >= maxModuleDepth {
+		return nil, nil
+	}
+
+	moduleFiles, err := findModuleFiles(moduleDir)
+	if err != nil || len(moduleFiles) == 0 {
+		return nil, nil
+	}
+
+	// 呼び出し元のvar/localで評価できる引数をモジュールの入力値とする
+	inputs := make(map[string]cty.Value)
+	if syntaxBody, ok := block.Body.(*hclsyntax.Body); ok {
+		for name, attr := range syntaxBody.Attributes {
+			if moduleMetaArguments[name] {
+				continue
+			}
+			if value, diags := attr.Expr.Value(metaCtx); !diags.HasErrors() && value.IsWhollyKnown() {
+				inputs[name] = value
+			}
+		}
+	}
+
+	p.moduleStack[moduleDir] = true
+	defer delete(p.moduleStack, moduleDir)
+
+	return p.parseFiles(moduleFiles, inputs, true)
+}
+
+// findModuleFiles はモジュールディレクトリ内の.tfファイルを検索する
+func findModuleFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var moduleFiles []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".tf" {
+			moduleFiles = append(moduleFiles, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	sort.Strings(moduleFiles) // ファイル順序を一定にする
+	return moduleFiles, nil
+}
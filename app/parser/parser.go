@@ -2,49 +2,113 @@ package parser
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/Mkamono/tfspec/app/cache"
 	"github.com/Mkamono/tfspec/app/types"
 	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
 
+// HCLParser は環境のファイルを解析する（複数の環境を並行して解析できる）
 type HCLParser struct {
-	parser *hclparse.Parser
-	// ファイルのソースバイト列をキャッシュ（Range.SliceBytes用）
-	sourceCache map[string][]byte
+	// 構文解析済みファイルのキャッシュ（ソースバイト列はRange.SliceBytesに使用する）
+	files *fileCache
+	// 解析中のローカルモジュールディレクトリ（循環参照の検出用）
+	moduleStack map[string]bool
+	// ファイルごとの解析結果のキャッシュ（nilの場合は使用しない）
+	cache *cache.Store
+	// 解析中のトップレベルのファイルが読み込んだローカルモジュールのディレクトリ（キャッシュの依存関係として記録する）
+	// モジュール内から呼び出したモジュールも同じセッションで解析するため、ネストしたモジュールも全て含まれる
+	// 読み込むたびに記録するため、他のファイルが先に読み込んだモジュールも含まれる
+	moduleDirs []string
 }
 
 func NewHCLParser() *HCLParser {
 	return &HCLParser{
-		parser:      hclparse.NewParser(),
-		sourceCache: make(map[string][]byte),
+		files:       newFileCache(),
+		moduleStack: make(map[string]bool),
 	}
 }
 
 // ParseMultipleFiles は複数の.tf/.hclファイルを結合して解析する
+// 並行して呼び出せるよう、ファイルのキャッシュのみを共有し、循環参照の検出状態は呼び出しごとに持つ
 func (p *HCLParser) ParseMultipleFiles(filenames []string) (*types.EnvResources, error) {
+	session := &HCLParser{
+		files:       p.files,
+		moduleStack: make(map[string]bool),
+		cache:       p.cache,
+	}
+	return session.parseFiles(filenames, session.loadTfvars(filenames, &hcl.EvalContext{Functions: metaFunctions()}), false)
+}
+
+// SetCache はファイルごとの解析結果のキャッシュを設定する（nilの場合はキャッシュを使用しない）
+func (p *HCLParser) SetCache(store *cache.Store) {
+	p.cache = store
+}
+
+// parseFiles は複数ファイルを結合して解析する
+// varValues: variableのdefaultを上書きする値（tfvarsやモジュール入力）
+// evaluateAttrs: trueの場合は属性値をvar/localを含むコンテキストで評価する（モジュールの具体化用）
+func (p *HCLParser) parseFiles(filenames []string, varValues map[string]cty.Value, evaluateAttrs bool) (*types.EnvResources, error) {
+	// terragrunt.hclはTerraformのブロックとは別に解析する
+	var terragrunt *types.EnvTerragrunt
+	var terraformFiles []string
+	for _, filename := range filenames {
+		if !isTerragruntFile(filename) {
+			terraformFiles = append(terraformFiles, filename)
+			continue
+		}
+
+		envTerragrunt, err := p.parseTerragruntFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		terragrunt = envTerragrunt
+	}
+	filenames = terraformFiles
+
+	// トップレベルのファイルはキャッシュを使用する（モジュール内のファイルは呼び出し元の解析結果に含まれる）
+	var fragments []*types.EnvResources
+	var err error
+	if p.cache != nil && len(p.moduleStack) == 0 {
+		fragments, err = p.parseFilesWithCache(filenames, varValues)
+	} else {
+		fragments, err = p.parseFileFragments(filenames, varValues, evaluateAttrs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	var allResources []*types.EnvResource
 	var allModules []*types.EnvModule
 	var allLocals []*types.EnvLocal
 	var allVariables []*types.EnvVariable
 	var allOutputs []*types.EnvOutput
 	var allDataSources []*types.EnvData
-
-	// 各ファイルを順番に解析して結合
-	for _, filename := range filenames {
-		envResources, err := p.ParseEnvFile(filename)
-		if err != nil {
-			return nil, err
-		}
+	var allProviders []*types.EnvProvider
+	var terraform *types.EnvTerraform
+	var allMoved []*types.EnvMoved
+	var allImports []*types.EnvImport
+	var allRemoved []*types.EnvRemoved
+	var allChecks []*types.EnvCheck
+
+	// 各ファイルの解析結果を順番に結合
+	for _, envResources := range fragments {
 		allResources = append(allResources, envResources.Resources...)
 		allModules = append(allModules, envResources.Modules...)
 		allLocals = append(allLocals, envResources.Locals...)
 		allVariables = append(allVariables, envResources.Variables...)
 		allOutputs = append(allOutputs, envResources.Outputs...)
 		allDataSources = append(allDataSources, envResources.DataSources...)
+		allProviders = append(allProviders, envResources.Providers...)
+		terraform = mergeTerraform(terraform, envResources.Terraform)
+		allMoved = append(allMoved, envResources.Moved...)
+		allImports = append(allImports, envResources.Imports...)
+		allRemoved = append(allRemoved, envResources.Removed...)
+		allChecks = append(allChecks, envResources.Checks...)
 	}
 
 	return &types.EnvResources{
@@ -54,19 +118,55 @@ func (p *HCLParser) ParseMultipleFiles(filenames []string) (*types.EnvResources,
 		Variables:   allVariables,
 		Outputs:     allOutputs,
 		DataSources: allDataSources,
+		Providers:   allProviders,
+		Terraform:   terraform,
+		Terragrunt:  terragrunt,
+		Moved:       allMoved,
+		Imports:     allImports,
+		Removed:     allRemoved,
+		Checks:      allChecks,
 	}, nil
 }
 
-// 標準的なTerraform HCLファイル解析（カスタム関数なし）
-func (p *HCLParser) ParseEnvFile(filename string) (*types.EnvResources, error) {
-	file, diags := p.parser.ParseHCLFile(filename)
-	if diags.HasErrors() {
-		return nil, diags
+// parseFileFragments は各ファイルを解析し、ファイルごとの解析結果を順番に返す
+func (p *HCLParser) parseFileFragments(filenames []string, varValues map[string]cty.Value, evaluateAttrs bool) ([]*types.EnvResources, error) {
+	// 全ファイルを先に構文解析する（count / for_each の評価にファイル横断のvariable・localsが必要なため）
+	files := make(map[string]*hcl.File, len(filenames))
+	for _, filename := range filenames {
+		file, diags := p.files.parse(filename)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		files[filename] = file
 	}
 
-	// ソースバイト列をキャッシュに保存（Range.SliceBytes用）
-	p.sourceCache[filename] = file.Bytes
+	// メタ引数評価用コンテキスト
+	metaCtx := p.buildMetaEvalContext(files, filenames, varValues)
 
+	// 属性評価用コンテキスト（トップレベルでは参照式をソーステキストのまま残すため空）
+	attrCtx := &hcl.EvalContext{}
+	if evaluateAttrs {
+		attrCtx = metaCtx
+	}
+
+	fragments := make([]*types.EnvResources, 0, len(filenames))
+	for _, filename := range filenames {
+		envResources, err := p.parseFile(filename, files[filename], metaCtx, attrCtx)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, envResources)
+	}
+	return fragments, nil
+}
+
+// 標準的なTerraform HCLファイル解析（カスタム関数なし）
+func (p *HCLParser) ParseEnvFile(filename string) (*types.EnvResources, error) {
+	return p.ParseMultipleFiles([]string{filename})
+}
+
+// parseFile は構文解析済みの1ファイルからTerraformブロックを抽出する
+func (p *HCLParser) parseFile(filename string, file *hcl.File, metaCtx, attrCtx *hcl.EvalContext) (*types.EnvResources, error) {
 	// Terraformの全ブロックタイプを解析
 	content, _, diags := file.Body.PartialContent(&hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{
@@ -94,6 +194,30 @@ func (p *HCLParser) ParseEnvFile(filename string) (*types.EnvResources, error) {
 				Type:       "data",
 				LabelNames: []string{"type", "name"},
 			},
+			{
+				Type:       "terraform",
+				LabelNames: []string{},
+			},
+			{
+				Type:       "provider",
+				LabelNames: []string{"name"},
+			},
+			{
+				Type:       "moved",
+				LabelNames: []string{},
+			},
+			{
+				Type:       "import",
+				LabelNames: []string{},
+			},
+			{
+				Type:       "removed",
+				LabelNames: []string{},
+			},
+			{
+				Type:       "check",
+				LabelNames: []string{"name"},
+			},
 		},
 	})
 
@@ -107,37 +231,45 @@ func (p *HCLParser) ParseEnvFile(filename string) (*types.EnvResources, error) {
 	var variables []*types.EnvVariable
 	var outputs []*types.EnvOutput
 	var dataSources []*types.EnvData
+	var providers []*types.EnvProvider
+	var terraform *types.EnvTerraform
+	var moved []*types.EnvMoved
+	var imports []*types.EnvImport
+	var removed []*types.EnvRemoved
+	var checks []*types.EnvCheck
 
-	// 評価コンテキスト（空）
-	evalCtx := &hcl.EvalContext{}
+	// 属性の評価コンテキスト
+	evalCtx := attrCtx
 
 	// 各ブロックタイプを処理
 	for _, block := range content.Blocks {
 		switch block.Type {
 		case "resource":
-			envResource := &types.EnvResource{
-				Type:   block.Labels[0],
-				Name:   block.Labels[1],
-				Attrs:  make(map[string]cty.Value),
-				Blocks: make(map[string][]*types.EnvBlock),
-			}
-
-			if err := p.parseResourceContent(block.Body, filename, evalCtx, envResource); err != nil {
+			instances, err := p.parseResourceInstances(block, filename, metaCtx, evalCtx)
+			if err != nil {
 				return nil, err
 			}
 
-			resources = append(resources, envResource)
+			resources = append(resources, instances...)
 
 		case "module":
 			envModule := &types.EnvModule{
-				Name:  block.Labels[0],
-				Attrs: make(map[string]cty.Value),
+				Name:     block.Labels[0],
+				Attrs:    make(map[string]cty.Value),
+				Location: sourceLocation(block.DefRange),
 			}
 
 			if err := p.parseSimpleBlockContent(block.Body, filename, evalCtx, envModule.Attrs); err != nil {
 				return nil, err
 			}
 
+			// ローカルモジュールは入力値で具体化して内部構成を解析
+			children, err := p.parseLocalModule(block, filename, metaCtx, envModule.Attrs)
+			if err != nil {
+				return nil, err
+			}
+			envModule.Children = children
+
 			modules = append(modules, envModule)
 
 		case "locals":
@@ -147,8 +279,9 @@ func (p *HCLParser) ParseEnvFile(filename string) (*types.EnvResources, error) {
 
 		case "variable":
 			envVariable := &types.EnvVariable{
-				Name:  block.Labels[0],
-				Attrs: make(map[string]cty.Value),
+				Name:     block.Labels[0],
+				Attrs:    make(map[string]cty.Value),
+				Location: sourceLocation(block.DefRange),
 			}
 
 			if err := p.parseSimpleBlockContent(block.Body, filename, evalCtx, envVariable.Attrs); err != nil {
@@ -159,8 +292,9 @@ func (p *HCLParser) ParseEnvFile(filename string) (*types.EnvResources, error) {
 
 		case "output":
 			envOutput := &types.EnvOutput{
-				Name:  block.Labels[0],
-				Attrs: make(map[string]cty.Value),
+				Name:     block.Labels[0],
+				Attrs:    make(map[string]cty.Value),
+				Location: sourceLocation(block.DefRange),
 			}
 
 			if err := p.parseSimpleBlockContent(block.Body, filename, evalCtx, envOutput.Attrs); err != nil {
@@ -170,23 +304,75 @@ func (p *HCLParser) ParseEnvFile(filename string) (*types.EnvResources, error) {
 			outputs = append(outputs, envOutput)
 
 		case "data":
-			envData := &types.EnvData{
-				Type:   block.Labels[0],
-				Name:   block.Labels[1],
-				Attrs:  make(map[string]cty.Value),
-				Blocks: make(map[string][]*types.EnvBlock),
+			instances, err := p.parseResourceInstances(block, filename, metaCtx, evalCtx)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, instance := range instances {
+				dataSources = append(dataSources, &types.EnvData{
+					Type:     instance.Type,
+					Name:     instance.Name,
+					Key:      instance.Key,
+					Attrs:    instance.Attrs,
+					Blocks:   instance.Blocks,
+					Location: instance.Location,
+				})
 			}
 
-			if err := p.parseResourceContent(block.Body, filename, evalCtx, &types.EnvResource{
-				Type:   envData.Type,
-				Name:   envData.Name,
-				Attrs:  envData.Attrs,
-				Blocks: envData.Blocks,
-			}); err != nil {
+		case "terraform":
+			envTerraform, err := p.parseTerraformBlock(block.Body, filename, evalCtx)
+			if err != nil {
+				return nil, err
+			}
+
+			terraform = mergeTerraform(terraform, envTerraform)
+
+		case "provider":
+			envProvider, err := p.parseProviderBlock(block, filename, evalCtx, metaCtx)
+			if err != nil {
+				return nil, err
+			}
+
+			providers = append(providers, envProvider)
+
+		case "moved":
+			attrs := make(map[string]cty.Value)
+			if err := p.parseSimpleBlockContent(block.Body, filename, evalCtx, attrs); err != nil {
 				return nil, err
 			}
 
-			dataSources = append(dataSources, envData)
+			moved = append(moved, &types.EnvMoved{
+				From:  takeAddressAttr(attrs, "from"),
+				Attrs: attrs,
+			})
+
+		case "import":
+			attrs := make(map[string]cty.Value)
+			if err := p.parseSimpleBlockContent(block.Body, filename, evalCtx, attrs); err != nil {
+				return nil, err
+			}
+
+			imports = append(imports, &types.EnvImport{
+				To:    takeAddressAttr(attrs, "to"),
+				Attrs: attrs,
+			})
+
+		case "removed":
+			envRemoved, err := p.parseRemovedBlock(block, filename, evalCtx, metaCtx)
+			if err != nil {
+				return nil, err
+			}
+
+			removed = append(removed, envRemoved)
+
+		case "check":
+			envCheck, err := p.parseCheckBlock(block, filename, evalCtx, metaCtx)
+			if err != nil {
+				return nil, err
+			}
+
+			checks = append(checks, envCheck)
 		}
 	}
 
@@ -197,12 +383,18 @@ func (p *HCLParser) ParseEnvFile(filename string) (*types.EnvResources, error) {
 		Variables:   variables,
 		Outputs:     outputs,
 		DataSources: dataSources,
+		Providers:   providers,
+		Terraform:   terraform,
+		Moved:       moved,
+		Imports:     imports,
+		Removed:     removed,
+		Checks:      checks,
 	}, nil
 }
 
 // parseAttributesFromBody はHCL Bodyから属性を解析する汎用ヘルパー関数
 func (p *HCLParser) parseAttributesFromBody(body hcl.Body, filename string, evalCtx *hcl.EvalContext, attrs map[string]cty.Value) error {
-	sourceBytes := p.sourceCache[filename]
+	sourceBytes := p.files.source(filename)
 
 	// 低レベルのhclsyntax.Bodyを試す
 	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
@@ -240,9 +432,16 @@ func (p *HCLParser) parseAttributesFromBody(body hcl.Body, filename string, eval
 }
 
 // リソース内のコンテンツを再帰的に解析（属性とネストブロック）
-func (p *HCLParser) parseResourceContent(body hcl.Body, filename string, evalCtx *hcl.EvalContext, resource *types.EnvResource) error {
+// metaCtx はdynamicブロックの for_each 評価に使用する
+func (p *HCLParser) parseResourceContent(body hcl.Body, filename string, evalCtx, metaCtx *hcl.EvalContext, resource *types.EnvResource) error {
+	return p.parseBlockContent(body, filename, evalCtx, metaCtx, resource.Attrs, resource.Blocks)
+}
+
+// parseBlockContent はブロック本体の属性とネストブロックを解析する
+// ネストブロック・dynamicブロックの content の中のネストブロックも同じように再帰的に解析する
+func (p *HCLParser) parseBlockContent(body hcl.Body, filename string, evalCtx, metaCtx *hcl.EvalContext, attrs map[string]cty.Value, blocks map[string][]*types.EnvBlock) error {
 	// 属性を解析
-	if err := p.parseAttributesFromBody(body, filename, evalCtx, resource.Attrs); err != nil {
+	if err := p.parseAttributesFromBody(body, filename, evalCtx, attrs); err != nil {
 		return err
 	}
 
@@ -253,19 +452,28 @@ func (p *HCLParser) parseResourceContent(body hcl.Body, filename string, evalCtx
 	}
 
 	for _, block := range syntaxBody.Blocks {
+		// dynamicブロックは展開して具体的なネストブロックとして扱う
+		if block.Type == "dynamic" && len(block.Labels) == 1 {
+			if err := p.parseDynamicBlock(block, filename, evalCtx, metaCtx, blocks); err != nil {
+				return err
+			}
+			continue
+		}
+
 		envBlock := &types.EnvBlock{
 			Type:   block.Type,
 			Labels: block.Labels,
 			Attrs:  make(map[string]cty.Value),
+			Blocks: make(map[string][]*types.EnvBlock),
 		}
 
-		// ネストブロック内の属性を解析
-		if err := p.parseAttributesFromBody(block.Body, filename, evalCtx, envBlock.Attrs); err != nil {
+		// ネストブロック内の属性・ネストブロックを解析
+		if err := p.parseBlockContent(block.Body, filename, evalCtx, metaCtx, envBlock.Attrs, envBlock.Blocks); err != nil {
 			return err
 		}
 
 		// ブロック型別にグループ化
-		resource.Blocks[block.Type] = append(resource.Blocks[block.Type], envBlock)
+		blocks[block.Type] = append(blocks[block.Type], envBlock)
 	}
 
 	return nil
@@ -276,6 +484,35 @@ func (p *HCLParser) parseSimpleBlockContent(body hcl.Body, filename string, eval
 	return p.parseAttributesFromBody(body, filename, evalCtx, attrs)
 }
 
+// attributeRanges はブロック内の属性名 -> 属性の位置を返す
+func attributeRanges(body hcl.Body) map[string]hcl.Range {
+	ranges := make(map[string]hcl.Range)
+	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
+		for name, attr := range syntaxBody.Attributes {
+			ranges[name] = attr.NameRange
+		}
+		return ranges
+	}
+
+	hlAttrs, _ := body.JustAttributes()
+	for name, attr := range hlAttrs {
+		ranges[name] = attr.NameRange
+	}
+	return ranges
+}
+
+// sourceLocation はブロック・属性の位置を返す（同じファイルを実行時のディレクトリによらず識別できるよう絶対パスにする）
+func sourceLocation(rng hcl.Range) types.SourceLocation {
+	if rng.Filename == "" {
+		return types.SourceLocation{}
+	}
+	filename, err := filepath.Abs(rng.Filename)
+	if err != nil {
+		filename = rng.Filename
+	}
+	return types.SourceLocation{Filename: filename, Line: rng.Start.Line}
+}
+
 // parseLocalsContent はlocalsブロック内のローカル変数を解析
 func (p *HCLParser) parseLocalsContent(body hcl.Body, filename string, evalCtx *hcl.EvalContext, locals *[]*types.EnvLocal) error {
 	// 一時的な属性マップを作成
@@ -285,10 +522,12 @@ func (p *HCLParser) parseLocalsContent(body hcl.Body, filename string, evalCtx *
 	}
 
 	// 属性をEnvLocalに変換
+	ranges := attributeRanges(body)
 	for name, value := range attrs {
 		envLocal := &types.EnvLocal{
-			Name:  name,
-			Value: value,
+			Name:     name,
+			Value:    value,
+			Location: sourceLocation(ranges[name]),
 		}
 		*locals = append(*locals, envLocal)
 	}
@@ -459,3 +698,20 @@ func loadIgnoreDirectory(dirPath string) ([]string, error) {
 
 	return rules, nil
 }
+
+// LoadSeverityRules は.tfspecseverityファイル（重要度ルール）の読み込みを行う
+func LoadSeverityRules(tfspecDir string) ([]string, error) {
+	// .tfspecディレクトリが存在しない場合は空のルールを返す
+	if tfspecDir == "" {
+		return []string{}, nil
+	}
+
+	content, err := os.ReadFile(tfspecDir + "/.tfspecseverity")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	return parseIgnoreContent(string(content)), nil
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// parseTerraformBlock はterraformブロック（required_version, required_providers, backend）を解析する
+func (p *HCLParser) parseTerraformBlock(body hcl.Body, filename string, evalCtx *hcl.EvalContext) (*types.EnvTerraform, error) {
+	envTerraform := &types.EnvTerraform{
+		Attrs:             make(map[string]cty.Value),
+		RequiredProviders: make(map[string]map[string]cty.Value),
+	}
+
+	if err := p.parseAttributesFromBody(body, filename, evalCtx, envTerraform.Attrs); err != nil {
+		return nil, err
+	}
+
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return envTerraform, nil
+	}
+
+	for _, block := range syntaxBody.Blocks {
+		switch block.Type {
+		case "required_providers":
+			providerAttrs := make(map[string]cty.Value)
+			if err := p.parseAttributesFromBody(block.Body, filename, evalCtx, providerAttrs); err != nil {
+				return nil, err
+			}
+
+			for name, value := range providerAttrs {
+				envTerraform.RequiredProviders[name] = requiredProviderAttrs(value)
+			}
+
+		case "backend":
+			if len(block.Labels) != 1 {
+				continue
+			}
+
+			backend := &types.EnvBackend{
+				Type:  block.Labels[0],
+				Attrs: make(map[string]cty.Value),
+			}
+			if err := p.parseAttributesFromBody(block.Body, filename, evalCtx, backend.Attrs); err != nil {
+				return nil, err
+			}
+
+			envTerraform.Backend = backend
+		}
+	}
+
+	return envTerraform, nil
+}
+
+// requiredProviderAttrs はrequired_providersの1エントリを属性マップに変換する
+// 旧形式（aws = "~> 3.0"）はversion属性として扱う
+func requiredProviderAttrs(value cty.Value) map[string]cty.Value {
+	if !value.IsNull() && value.IsKnown() && (value.Type().IsObjectType() || value.Type().IsMapType()) {
+		return value.AsValueMap()
+	}
+	return map[string]cty.Value{"version": value}
+}
+
+// mergeTerraform は複数のterraformブロックを1つに結合する（後から現れた定義で上書き）
+func mergeTerraform(base, other *types.EnvTerraform) *types.EnvTerraform {
+	if other == nil {
+		return base
+	}
+	if base == nil {
+		return other
+	}
+
+	for name, value := range other.Attrs {
+		base.Attrs[name] = value
+	}
+	for name, attrs := range other.RequiredProviders {
+		base.RequiredProviders[name] = attrs
+	}
+	if other.Backend != nil {
+		base.Backend = other.Backend
+	}
+
+	return base
+}
+
+// parseProviderBlock はproviderブロックを解析する
+func (p *HCLParser) parseProviderBlock(block *hcl.Block, filename string, evalCtx, metaCtx *hcl.EvalContext) (*types.EnvProvider, error) {
+	// ネストブロック（assume_role, default_tags等）も扱うためリソースと同じ解析を使う
+	resource := &types.EnvResource{
+		Type:   "provider",
+		Name:   block.Labels[0],
+		Attrs:  make(map[string]cty.Value),
+		Blocks: make(map[string][]*types.EnvBlock),
+	}
+	if err := p.parseResourceContent(block.Body, filename, evalCtx, metaCtx, resource); err != nil {
+		return nil, err
+	}
+
+	envProvider := &types.EnvProvider{
+		Name:   block.Labels[0],
+		Attrs:  resource.Attrs,
+		Blocks: resource.Blocks,
+	}
+
+	// aliasはアドレスの一部として扱い、属性比較の対象からは外す
+	if alias, exists := envProvider.Attrs["alias"]; exists {
+		if !alias.IsNull() && alias.Type() == cty.String {
+			envProvider.Alias = alias.AsString()
+		}
+		delete(envProvider.Attrs, "alias")
+	}
+
+	return envProvider, nil
+}
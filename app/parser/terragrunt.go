@@ -0,0 +1,339 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// terragruntFileName はTerragruntの構成ファイル名
+const terragruntFileName = "terragrunt.hcl"
+
+// maxIncludeDepth はterragruntのincludeを辿る最大の深さ
+const maxIncludeDepth = 10
+
+// terragruntMergeStrategies はincludeブロックのmerge_strategyに指定できる値
+var terragruntMergeStrategies = map[string]bool{
+	"no_merge": true,
+	"shallow":  true,
+	"deep":     true,
+}
+
+// isTerragruntFile はTerragruntの構成ファイルかどうかを判定する
+func isTerragruntFile(filename string) bool {
+	return filepath.Base(filename) == terragruntFileName
+}
+
+// parseTerragruntFile はterragrunt.hclを解析し、includeした親の構成を結合する
+func (p *HCLParser) parseTerragruntFile(filename string) (*types.EnvTerragrunt, error) {
+	absFilename, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	return p.parseTerragruntConfig(absFilename, filepath.Dir(absFilename), make(map[string]bool))
+}
+
+// parseTerragruntConfig は1つのTerragrunt構成ファイルを解析する
+// terragruntDir: 解析の起点となった環境のディレクトリ（includeのpathで使うget_terragrunt_dir()の値）
+// includeStack: 解析中のファイル（循環includeの検出用）
+func (p *HCLParser) parseTerragruntConfig(filename, terragruntDir string, includeStack map[string]bool) (*types.EnvTerragrunt, error) {
+	if includeStack[filename] {
+		return nil, fmt.Errorf("terragruntのincludeが循環しています: %s", filename)
+	}
+	if len(includeStack) >= maxIncludeDepth {
+		return nil, fmt.Errorf("terragruntのincludeが深すぎます（最大%d段）: %s", maxIncludeDepth, filename)
+	}
+	includeStack[filename] = true
+	defer delete(includeStack, filename)
+
+	file, diags := p.files.parse(filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	envTerragrunt := &types.EnvTerragrunt{
+		Attrs:          make(map[string]cty.Value),
+		Inputs:         make(map[string]cty.Value),
+		TerraformAttrs: make(map[string]cty.Value),
+	}
+
+	syntaxBody, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return envTerragrunt, nil
+	}
+
+	// 属性はlocalsのみで評価する（パスに依存する関数・dependencyの参照を含む式はソーステキストのまま比較する）
+	evalCtx := terragruntEvalContext(syntaxBody)
+
+	if err := p.parseAttributesFromBody(syntaxBody, filename, evalCtx, envTerragrunt.Attrs); err != nil {
+		return nil, err
+	}
+	if attr, exists := syntaxBody.Attributes["inputs"]; exists {
+		if inputs, ok := p.evaluateObjectItems(attr.Expr, filename, evalCtx); ok {
+			envTerragrunt.Inputs = inputs
+			delete(envTerragrunt.Attrs, "inputs")
+		}
+	}
+
+	var includes []*hclsyntax.Block
+	for _, block := range syntaxBody.Blocks {
+		switch block.Type {
+		case "include":
+			includes = append(includes, block)
+
+		case "terraform":
+			if err := p.parseAttributesFromBody(block.Body, filename, evalCtx, envTerragrunt.TerraformAttrs); err != nil {
+				return nil, err
+			}
+
+		case "remote_state":
+			envTerragrunt.RemoteState = p.parseRemoteState(block, filename, evalCtx)
+		}
+	}
+
+	// includeした構成を順に結合し、最後に自身の構成で上書きする
+	var merged *types.EnvTerragrunt
+	deep := false
+	for _, include := range includes {
+		includeFile, mergeStrategy, err := resolveInclude(include, filename, terragruntDir)
+		if err != nil {
+			return nil, err
+		}
+		if mergeStrategy == "no_merge" {
+			continue
+		}
+
+		included, err := p.parseTerragruntConfig(includeFile, terragruntDir, includeStack)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeTerragrunt(merged, included, false)
+		deep = deep || mergeStrategy == "deep"
+	}
+
+	return mergeTerragrunt(merged, envTerragrunt, deep), nil
+}
+
+// terragruntEvalContext はlocalsを評価したコンテキストを構築する
+func terragruntEvalContext(body *hclsyntax.Body) *hcl.EvalContext {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"local": cty.EmptyObjectVal,
+		},
+		Functions: metaFunctions(),
+	}
+
+	localExprs := make(map[string]hcl.Expression)
+	for _, block := range body.Blocks {
+		if block.Type != "locals" {
+			continue
+		}
+		for name, attr := range block.Body.Attributes {
+			localExprs[name] = attr.Expr
+		}
+	}
+	evaluateLocals(ctx, localExprs)
+
+	return ctx
+}
+
+// parseRemoteState はremote_stateブロックのbackendとconfigを解析する
+func (p *HCLParser) parseRemoteState(block *hclsyntax.Block, filename string, evalCtx *hcl.EvalContext) *types.EnvBackend {
+	backend := &types.EnvBackend{
+		Attrs: make(map[string]cty.Value),
+	}
+
+	if attr, exists := block.Body.Attributes["backend"]; exists {
+		if value := p.evaluateExpr(attr.Expr, filename, evalCtx); value.Type() == cty.String && !value.IsNull() {
+			backend.Type = value.AsString()
+		}
+	}
+	if attr, exists := block.Body.Attributes["config"]; exists {
+		if config, ok := p.evaluateObjectItems(attr.Expr, filename, evalCtx); ok {
+			backend.Attrs = config
+		} else {
+			backend.Attrs["config"] = p.evaluateExpr(attr.Expr, filename, evalCtx)
+		}
+	}
+
+	return backend
+}
+
+// evaluateObjectItems はオブジェクトの式をキーごとに評価する
+// オブジェクトのリテラルは要素ごとに評価するため、一部の値が評価できない場合もその値だけソーステキストになる
+func (p *HCLParser) evaluateObjectItems(expr hcl.Expression, filename string, evalCtx *hcl.EvalContext) (map[string]cty.Value, bool) {
+	if objectExpr, ok := expr.(*hclsyntax.ObjectConsExpr); ok {
+		items := make(map[string]cty.Value)
+		for _, item := range objectExpr.Items {
+			key, diags := item.KeyExpr.Value(evalCtx)
+			if diags.HasErrors() || key.IsNull() || !key.IsKnown() || key.Type() != cty.String {
+				return nil, false
+			}
+			items[key.AsString()] = p.evaluateExpr(item.ValueExpr, filename, evalCtx)
+		}
+		return items, true
+	}
+
+	value, diags := expr.Value(evalCtx)
+	if diags.HasErrors() || value.IsNull() || !value.IsWhollyKnown() {
+		return nil, false
+	}
+	if !value.Type().IsObjectType() && !value.Type().IsMapType() {
+		return nil, false
+	}
+
+	items := make(map[string]cty.Value)
+	for key, item := range value.AsValueMap() {
+		items[key] = item
+	}
+	return items, true
+}
+
+// evaluateExpr は式を評価する（評価できない場合はソーステキストを値とする）
+func (p *HCLParser) evaluateExpr(expr hcl.Expression, filename string, evalCtx *hcl.EvalContext) cty.Value {
+	value, diags := expr.Value(evalCtx)
+	if diags.HasErrors() || !value.IsWhollyKnown() {
+		return cty.StringVal(string(expr.Range().SliceBytes(p.files.source(filename))))
+	}
+	return value
+}
+
+// resolveInclude はincludeブロックのpathを評価し、includeするファイルとmerge_strategyを返す
+func resolveInclude(block *hclsyntax.Block, filename, terragruntDir string) (string, string, error) {
+	pathAttr, exists := block.Body.Attributes["path"]
+	if !exists {
+		return "", "", fmt.Errorf("terragruntのincludeブロックにpathがありません: %s", filename)
+	}
+
+	includeCtx := &hcl.EvalContext{Functions: terragruntPathFunctions(filepath.Dir(filename), terragruntDir)}
+	value, diags := pathAttr.Expr.Value(includeCtx)
+	if diags.HasErrors() || value.IsNull() || !value.IsKnown() || value.Type() != cty.String {
+		return "", "", fmt.Errorf("terragruntのincludeのpathを評価できません:\n  ファイル: %s\n  エラー: %s\n"+
+			"ヒント: pathにはローカルファイルのパス（find_in_parent_folders() 等）を指定してください", filename, diags.Error())
+	}
+
+	includeFile := value.AsString()
+	if !filepath.IsAbs(includeFile) {
+		includeFile = filepath.Join(filepath.Dir(filename), includeFile)
+	}
+	if _, err := os.Stat(includeFile); err != nil {
+		return "", "", fmt.Errorf("terragruntのincludeしたファイルが見つかりません:\n  ファイル: %s\n  include: %s", filename, includeFile)
+	}
+
+	mergeStrategy := "shallow"
+	if attr, exists := block.Body.Attributes["merge_strategy"]; exists {
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || value.IsNull() || value.Type() != cty.String || !terragruntMergeStrategies[value.AsString()] {
+			return "", "", fmt.Errorf("terragruntのincludeのmerge_strategyが正しくありません: %s\n"+
+				"ヒント: no_merge, shallow, deep のいずれかを指定してください", filename)
+		}
+		mergeStrategy = value.AsString()
+	}
+
+	return filepath.Clean(includeFile), mergeStrategy, nil
+}
+
+// terragruntPathFunctions はincludeのpathの評価で利用できるTerragruntの関数群
+// dir: 評価するファイルのディレクトリ、terragruntDir: 解析の起点となった環境のディレクトリ
+func terragruntPathFunctions(dir, terragruntDir string) map[string]function.Function {
+	functions := metaFunctions()
+
+	// find_in_parent_folders(name, fallback) は親ディレクトリを遡ってファイルを探す（nameの省略時はterragrunt.hcl）
+	functions["find_in_parent_folders"] = function.New(&function.Spec{
+		VarParam: &function.Parameter{Name: "args", Type: cty.String},
+		Type:     function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			name := terragruntFileName
+			if len(args) > 0 {
+				name = args[0].AsString()
+			}
+			for current := filepath.Dir(dir); ; current = filepath.Dir(current) {
+				candidate := filepath.Join(current, name)
+				if _, err := os.Stat(candidate); err == nil {
+					return cty.StringVal(candidate), nil
+				}
+				if filepath.Dir(current) == current {
+					break
+				}
+			}
+			if len(args) > 1 {
+				return args[1], nil
+			}
+			return cty.NilVal, fmt.Errorf("親ディレクトリに %s が見つかりません", name)
+		},
+	})
+	functions["get_terragrunt_dir"] = function.New(&function.Spec{
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal(terragruntDir), nil
+		},
+	})
+
+	return functions
+}
+
+// mergeTerragrunt はincludeした構成に構成を結合する（後から結合した構成で上書き）
+// deep: inputsのオブジェクト・マップを再帰的に結合し、同じbackendのremote_stateのconfigを結合する
+func mergeTerragrunt(base, other *types.EnvTerragrunt, deep bool) *types.EnvTerragrunt {
+	if other == nil {
+		return base
+	}
+	if base == nil {
+		return other
+	}
+
+	for name, value := range other.Attrs {
+		base.Attrs[name] = value
+	}
+	for name, value := range other.Inputs {
+		if baseValue, exists := base.Inputs[name]; exists && deep {
+			value = deepMergeValue(baseValue, value)
+		}
+		base.Inputs[name] = value
+	}
+	for name, value := range other.TerraformAttrs {
+		base.TerraformAttrs[name] = value
+	}
+
+	switch {
+	case other.RemoteState == nil:
+	case deep && base.RemoteState != nil && base.RemoteState.Type == other.RemoteState.Type:
+		for name, value := range other.RemoteState.Attrs {
+			base.RemoteState.Attrs[name] = value
+		}
+	default:
+		base.RemoteState = other.RemoteState
+	}
+
+	return base
+}
+
+// deepMergeValue はオブジェクト・マップの値を再帰的に結合する（それ以外の値はotherで上書き）
+func deepMergeValue(base, other cty.Value) cty.Value {
+	if !isMergeableValue(base) || !isMergeableValue(other) {
+		return other
+	}
+
+	merged := make(map[string]cty.Value)
+	for name, value := range base.AsValueMap() {
+		merged[name] = value
+	}
+	for name, value := range other.AsValueMap() {
+		if baseValue, exists := merged[name]; exists {
+			value = deepMergeValue(baseValue, value)
+		}
+		merged[name] = value
+	}
+	return cty.ObjectVal(merged)
+}
+
+// isMergeableValue は再帰的に結合できる値（既知のオブジェクト・マップ）かどうかを判定する
+func isMergeableValue(value cty.Value) bool {
+	return !value.IsNull() && value.IsWhollyKnown() && (value.Type().IsObjectType() || value.Type().IsMapType())
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/Mkamono/tfspec/app/types"
 	"github.com/olekukonko/tablewriter"
 	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -34,6 +35,26 @@ func (r *ResultReporter) GenerateMarkdown(diffs []*types.DiffResult, envNames []
 	return r.generateMarkdownReport(driftTable, ignoredTable, envNames)
 }
 
+// GenerateHistoryMarkdown は単一環境の変更履歴をMarkdown形式で生成する
+// columns は比較する時点（基準ref, 現在）で、diffs は基準refからの変更
+func (r *ResultReporter) GenerateHistoryMarkdown(diffs []*types.DiffResult, envName string, columns []string, envResources map[string]*types.EnvResources, maxValueLength int, trimCell bool) string {
+	r.maxValueLength = maxValueLength
+	r.trimCell = trimCell
+	changeTable, _ := r.buildTables(diffs, columns, nil, envResources)
+
+	var md strings.Builder
+	md.WriteString("# Tfspec History: " + envName + "\n\n")
+	md.WriteString("## " + columns[0] + " からの変更\n\n")
+	if len(changeTable) > 0 {
+		md.WriteString(r.buildHierarchicalMarkdownTable(changeTable, columns, false))
+		md.WriteString("\n")
+	} else {
+		md.WriteString("変更はありません。\n\n")
+	}
+
+	return md.String()
+}
+
 // buildTables は差分データをテーブル形式に変換する
 func (r *ResultReporter) buildTables(diffs []*types.DiffResult, envNames []string, ruleComments map[string]string, envResources map[string]*types.EnvResources) ([]types.TableRow, []types.TableRow) {
 	driftRows := make(map[string]*types.TableRow)
@@ -50,28 +71,34 @@ func (r *ResultReporter) buildTables(diffs []*types.DiffResult, envNames []strin
 		}
 
 		row := r.getOrCreateRow(targetMap, key, diff.Resource, diff.Path)
+		if types.SeverityRank(diff.Severity) > types.SeverityRank(row.Severity) {
+			row.Severity = diff.Severity
+		}
 
-		// 値の設定
-		if diff.Path == "" && strings.HasPrefix(diff.Resource, "local.") {
-			// local存在差分の場合は実際の値を取得
-			row.Values[diff.Environment] = r.getLocalValueMarkdown(envResources[diff.Environment], diff.Resource)
-		} else if diff.Path == "" && strings.HasPrefix(diff.Resource, "var.") {
-			// variable存在差分の場合は実際の値を取得
-			row.Values[diff.Environment] = r.getVariableValueMarkdown(envResources[diff.Environment], diff.Resource)
+		// 値の設定（local・variableの存在差分の場合は実際の値を取得）
+		address := diff.Address()
+		if value, ok := r.getDefinitionValueMarkdown(envResources[diff.Environment], address); ok {
+			row.Values[diff.Environment] = value
 		} else {
 			row.Values[diff.Environment] = r.formatter.FormatValueWithMarkdown(diff.Actual, r.maxValueLength)
 		}
 
+		// モジュールのsource/version差分は分類を併記する
+		if label, exists := moduleChangeLabels[diff.Classification]; exists {
+			row.Values[diff.Environment] += " (" + label + ")"
+		}
+
+		// --base-ref指定時は基準refからの変化を併記する
+		if label, exists := refChangeLabels[diff.RefChange]; exists {
+			row.Values[diff.Environment] += " (" + label + ")"
+		}
+
 		// 期待値があればベース環境の値として設定
 		if !diff.Expected.IsNull() {
 			baseEnv := envNames[0]
 			if _, exists := row.Values[baseEnv]; !exists {
-				if diff.Path == "" && strings.HasPrefix(diff.Resource, "local.") {
-					// local存在差分の場合は実際の値を取得
-					row.Values[baseEnv] = r.getLocalValueMarkdown(envResources[baseEnv], diff.Resource)
-				} else if diff.Path == "" && strings.HasPrefix(diff.Resource, "var.") {
-					// variable存在差分の場合は実際の値を取得
-					row.Values[baseEnv] = r.getVariableValueMarkdown(envResources[baseEnv], diff.Resource)
+				if value, ok := r.getDefinitionValueMarkdown(envResources[baseEnv], address); ok {
+					row.Values[baseEnv] = value
 				} else {
 					row.Values[baseEnv] = r.formatter.FormatValueWithMarkdown(diff.Expected, r.maxValueLength)
 				}
@@ -89,6 +116,30 @@ func (r *ResultReporter) buildTables(diffs []*types.DiffResult, envNames []strin
 	return r.mapToSortedSlice(driftRows), r.mapToSortedSlice(ignoredRows)
 }
 
+// moduleChangeLabels はモジュールのsource/version差分の分類の表示名
+var moduleChangeLabels = map[string]string{
+	types.ModuleChangeModule:     "別モジュール",
+	types.ModuleChangeMajor:      "メジャー差分",
+	types.ModuleChangeMinor:      "マイナー差分",
+	types.ModuleChangePatch:      "パッチ差分",
+	types.ModuleChangeConstraint: "制約と固定の違い",
+}
+
+// severityLabels は差分の重要度の表示名（重要度ごとに色分けする）
+var severityLabels = map[string]string{
+	types.SeverityCritical: "🔴 critical",
+	types.SeverityHigh:     "🟠 high",
+	types.SeverityMedium:   "🟡 medium",
+	types.SeverityLow:      "🔵 low",
+	types.SeverityInfo:     "⚪ info",
+}
+
+// refChangeLabels は基準refからの差分の変化の表示名
+var refChangeLabels = map[string]string{
+	types.RefChangeNew:     "新規",
+	types.RefChangeChanged: "変更",
+}
+
 // getOrCreateRow は既存の行を取得するか新しい行を作成する
 func (r *ResultReporter) getOrCreateRow(targetMap map[string]*types.TableRow, key, resource, path string) *types.TableRow {
 	if row, exists := targetMap[key]; exists {
@@ -108,15 +159,48 @@ func (r *ResultReporter) getOrCreateRow(targetMap map[string]*types.TableRow, ke
 // enrichWithComments は無視されたルールにコメントを付与する
 func (r *ResultReporter) enrichWithComments(rows map[string]*types.TableRow, ruleComments map[string]string) {
 	for _, row := range rows {
-		for rule, comment := range ruleComments {
-			if strings.Contains(rule, row.Resource) && strings.Contains(rule, row.Path) {
+		if comment, found := findRuleComment(ruleComments, row.Resource, row.Path); found {
+			row.Comment = comment
+			continue
+		}
+		// count / for_each のインスタンスはインスタンスキーなしのルールにもマッチさせる
+		if address, err := types.ParseAddress(row.Resource); err == nil && address.Key != "" {
+			if comment, found := findRuleComment(ruleComments, address.WithoutKey().Resource(), row.Path); found {
 				row.Comment = comment
-				break
 			}
 		}
 	}
 }
 
+// findRuleComment はリソース・パスにマッチするルールのコメントを検索する
+// 差分の判定と同じく、ルールと一致するか親のルールの子パスの場合にマッチし、最も具体的な（長い）ルールを優先する
+func findRuleComment(ruleComments map[string]string, resource, path string) (string, bool) {
+	address := resource
+	if path != "" {
+		address += "." + path
+	}
+
+	// ルールにはオプション（allow=, expires=）が付いている場合がある
+	matchedRule, matchedPath := "", ""
+	for rule := range ruleComments {
+		fields := strings.Fields(rule)
+		if len(fields) == 0 {
+			continue
+		}
+		rulePath := fields[0]
+		if rulePath != address && !strings.HasPrefix(address, rulePath+".") {
+			continue
+		}
+		if len(rulePath) > len(matchedPath) || (len(rulePath) == len(matchedPath) && rule < matchedRule) {
+			matchedRule, matchedPath = rule, rulePath
+		}
+	}
+	if matchedPath == "" {
+		return "", false
+	}
+	return ruleComments[matchedRule], true
+}
+
 // fillMissingValues は欠損している環境の値を補填する
 func (r *ResultReporter) fillMissingValues(rows map[string]*types.TableRow, envNames []string, envResources map[string]*types.EnvResources) {
 	for _, row := range rows {
@@ -126,21 +210,24 @@ func (r *ResultReporter) fillMissingValues(rows map[string]*types.TableRow, envN
 			}
 
 			if envResource, exists := envResources[envName]; exists {
-				if row.Path == "" && strings.HasPrefix(row.Resource, "local.") {
-					// local値の補填
-					row.Values[envName] = r.getLocalValueMarkdown(envResource, row.Resource)
-				} else if row.Path == "" && strings.HasPrefix(row.Resource, "var.") {
-					// variable値の補填
-					row.Values[envName] = r.getVariableValueMarkdown(envResource, row.Resource)
+				address := (&types.DiffResult{Resource: row.Resource, Path: row.Path}).Address()
+				// モジュール内部の行はモジュールの内部構成を対象に補填する
+				scope := envResource.Scope(address.Module)
+				if scope == nil {
+					row.Values[envName] = ""
+				} else if value, ok := r.getDefinitionValueMarkdown(envResource, address); ok {
+					// local・variable値の補填
+					row.Values[envName] = value
 				} else {
-					// 通常のリソース処理
-					resource := r.findResource(envResource, row.Resource)
+					// 属性パスはdifferの無視ルールの検証と同じくEnvResources.Lookupで解決する
+					// （terraformブロック・terragrunt.hclも required_providers.aws.version, inputs.<キー> 等のパスでたどる）
+					resource := envResource.Lookup(address)
 					if resource != nil {
 						var value cty.Value
 						if row.Path == "" {
 							// リソース存在差分の場合
 							value = cty.BoolVal(true)
-						} else if val, exists := resource.Attrs[row.Path]; exists {
+						} else if val, exists := resource.Lookup(row.Path); exists {
 							value = val
 						} else {
 							value = cty.NullVal(cty.String)
@@ -160,13 +247,27 @@ func (r *ResultReporter) fillMissingValues(rows map[string]*types.TableRow, envN
 	}
 }
 
+// getDefinitionValueMarkdown はlocal・variableの存在差分の行に表示する実際の値を取得する
+// local・variableの存在差分でない場合はfalseを返す
+func (r *ResultReporter) getDefinitionValueMarkdown(envResource *types.EnvResources, address types.Address) (string, bool) {
+	if address.Path != "" {
+		return "", false
+	}
+	switch address.Kind {
+	case types.KindLocal:
+		return r.getLocalValueMarkdown(envResource.Scope(address.Module), address.Name), true
+	case types.KindVariable:
+		return r.getVariableValueMarkdown(envResource.Scope(address.Module), address.Name), true
+	}
+	return "", false
+}
+
 // getLocalValueMarkdown はlocal値をマークダウン形式で取得する
-func (r *ResultReporter) getLocalValueMarkdown(envResource *types.EnvResources, resourceName string) string {
+func (r *ResultReporter) getLocalValueMarkdown(envResource *types.EnvResources, localName string) string {
 	if envResource == nil {
 		return "-"
 	}
 
-	localName := strings.TrimPrefix(resourceName, "local.")
 	for _, local := range envResource.Locals {
 		if local.Name == localName {
 			return r.formatter.FormatValueWithMarkdown(local.Value, r.maxValueLength)
@@ -176,12 +277,11 @@ func (r *ResultReporter) getLocalValueMarkdown(envResource *types.EnvResources,
 }
 
 // getVariableValueMarkdown はvariable値をマークダウン形式で取得する
-func (r *ResultReporter) getVariableValueMarkdown(envResource *types.EnvResources, resourceName string) string {
+func (r *ResultReporter) getVariableValueMarkdown(envResource *types.EnvResources, varName string) string {
 	if envResource == nil {
 		return "-"
 	}
 
-	varName := strings.TrimPrefix(resourceName, "var.")
 	for _, variable := range envResource.Variables {
 		if variable.Name == varName {
 			if defaultVal, hasDefault := variable.Attrs["default"]; hasDefault && !defaultVal.IsNull() {
@@ -195,37 +295,6 @@ func (r *ResultReporter) getVariableValueMarkdown(envResource *types.EnvResource
 	return "-"
 }
 
-// findResource はリソースを名前で検索する（通常のresourceとdataリソース両方に対応）
-func (r *ResultReporter) findResource(envResources *types.EnvResources, resourceName string) *types.EnvResource {
-	// 通常のリソースを検索
-	for _, resource := range envResources.Resources {
-		fullName := resource.Type + "." + resource.Name
-		if fullName == resourceName {
-			return resource
-		}
-	}
-
-	// dataリソースを検索（data.aws_ami.ubuntu形式）
-	if strings.HasPrefix(resourceName, "data.") {
-		// "data." プレフィックスを削除
-		nameWithoutPrefix := strings.TrimPrefix(resourceName, "data.")
-		for _, dataSource := range envResources.DataSources {
-			fullName := dataSource.Type + "." + dataSource.Name
-			if fullName == nameWithoutPrefix {
-				// EnvData を EnvResource として扱えるように変換
-				return &types.EnvResource{
-					Type:   dataSource.Type,
-					Name:   dataSource.Name,
-					Attrs:  dataSource.Attrs,
-					Blocks: dataSource.Blocks,
-				}
-			}
-		}
-	}
-
-	return nil
-}
-
 // mapToSortedSlice はマップをソート済みスライスに変換する
 func (r *ResultReporter) mapToSortedSlice(rows map[string]*types.TableRow) []types.TableRow {
 	result := make([]types.TableRow, 0, len(rows))
@@ -269,16 +338,149 @@ func (r *ResultReporter) generateMarkdownReport(driftTable, ignoredTable []types
 }
 
 
+// GenerateStackSection はスタックのレポートを統合レポートの1セクションに変換する（見出しを1段下げる）
+func (r *ResultReporter) GenerateStackSection(stackName, report string) string {
+	return r.toSection("スタック: "+stackName, report)
+}
+
+// GenerateGroupMarkdown は環境グループ内の比較結果をMarkdownのセクションとして出力する
+func (r *ResultReporter) GenerateGroupMarkdown(groupName string, diffs []*types.DiffResult, envNames []string, ruleComments map[string]string, envResources map[string]*types.EnvResources, maxValueLength int, trimCell bool) string {
+	report := r.GenerateMarkdown(diffs, envNames, ruleComments, envResources, maxValueLength, trimCell)
+	return r.toSection("グループ内の差分: "+groupName+"（"+strings.Join(envNames, ", ")+"）", report)
+}
+
+// toSection はレポートのタイトルを除き、見出しを1段下げて指定した見出しのセクションにする
+func (r *ResultReporter) toSection(heading, report string) string {
+	report = strings.TrimPrefix(report, "# Tfspec Check Results\n\n")
+
+	lines := strings.Split(report, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			lines[i] = "#" + line
+		}
+	}
+
+	return "## " + heading + "\n\n" + strings.Join(lines, "\n")
+}
+
+// GeneratePendingChangesMarkdown はplanに含まれる未適用の変更をMarkdownで出力する（変更がない場合は空文字）
+func (r *ResultReporter) GeneratePendingChangesMarkdown(pendingChanges map[string][]*types.PendingChange, envNames []string) string {
+	if len(pendingChanges) == 0 {
+		return ""
+	}
+
+	var md strings.Builder
+	md.WriteString("## 未適用の変更（plan）\n\n")
+	md.WriteString("|環境|アドレス|アクション|\n")
+	md.WriteString("|:-:|:-|:-:|\n")
+
+	for _, envName := range envNames {
+		for i, change := range pendingChanges[envName] {
+			displayEnv := ""
+			if i == 0 {
+				displayEnv = tw.Title(envName)
+			}
+			md.WriteString("|" + displayEnv + "|" + change.Address + "|" + strings.Join(change.Actions, ", ") + "|\n")
+		}
+	}
+	md.WriteString("\n")
+
+	return md.String()
+}
+
+// GenerateResolvedDiffsMarkdown は基準refにはあったが解消された差分をMarkdownで出力する（--base-ref指定時のみ）
+func (r *ResultReporter) GenerateResolvedDiffsMarkdown(resolvedDiffs []*types.DiffResult, baseRef string) string {
+	if baseRef == "" {
+		return ""
+	}
+
+	var md strings.Builder
+	md.WriteString("## 解消された差分（" + baseRef + " との比較）\n\n")
+	if len(resolvedDiffs) == 0 {
+		md.WriteString("解消された差分はありません。\n\n")
+		return md.String()
+	}
+
+	sort.Slice(resolvedDiffs, func(i, j int) bool {
+		if resolvedDiffs[i].Resource != resolvedDiffs[j].Resource {
+			return resolvedDiffs[i].Resource < resolvedDiffs[j].Resource
+		}
+		if resolvedDiffs[i].Path != resolvedDiffs[j].Path {
+			return resolvedDiffs[i].Path < resolvedDiffs[j].Path
+		}
+		return resolvedDiffs[i].Environment < resolvedDiffs[j].Environment
+	})
+
+	md.WriteString("|リソース名|属性パス|環境|" + baseRef + " 時点の値|\n")
+	md.WriteString("|:-:|:-:|:-:|:-|\n")
+	for _, diff := range resolvedDiffs {
+		value := r.formatter.FormatValueWithMarkdown(diff.Actual, r.maxValueLength)
+		md.WriteString("|" + diff.Resource + "|" + diff.Path + "|" + tw.Title(diff.Environment) + "|" + value + "|\n")
+	}
+	md.WriteString("\n")
+
+	return md.String()
+}
+
+// GenerateBaselineMarkdown はベースラインで許容された構成ドリフトと、検出されなくなったベースラインの項目をMarkdownで出力する
+func (r *ResultReporter) GenerateBaselineMarkdown(baselineDiffs []*types.DiffResult, staleEntries []*types.BaselineEntry, envNames []string, envResources map[string]*types.EnvResources) string {
+	var md strings.Builder
+
+	if len(baselineDiffs) > 0 {
+		baselineTable, _ := r.buildTables(baselineDiffs, envNames, nil, envResources)
+		md.WriteString("## ベースラインで許容された差分\n\n")
+		md.WriteString(r.buildHierarchicalMarkdownTable(baselineTable, envNames, false))
+		md.WriteString("\n")
+	}
+
+	if len(staleEntries) > 0 {
+		md.WriteString("## 検出されなくなったベースラインの項目\n\n")
+		md.WriteString("|リソース名|属性パス|環境|記録時の値|\n")
+		md.WriteString("|:-:|:-:|:-:|:-|\n")
+		for _, entry := range staleEntries {
+			md.WriteString("|" + entry.Resource + "|" + entry.Path + "|" + tw.Title(entry.Environment) + "|" + string(entry.Actual) + "|\n")
+		}
+		md.WriteString("\n")
+	}
+
+	return md.String()
+}
+
+// GenerateDuplicatesMarkdown は環境内で重複した定義をエラーとしてMarkdownで出力する
+func (r *ResultReporter) GenerateDuplicatesMarkdown(duplicates []*types.DuplicateDefinition) string {
+	if len(duplicates) == 0 {
+		return ""
+	}
+
+	var md strings.Builder
+	md.WriteString("## 重複した定義（エラー）\n\n")
+	md.WriteString("同じ環境内で重複して定義されているため、全ての環境の比較から除外しました。\n\n")
+	md.WriteString("|種類|アドレス|環境|定義箇所|\n")
+	md.WriteString("|:-:|:-:|:-:|:-|\n")
+	for _, duplicate := range duplicates {
+		locations := make([]string, len(duplicate.Locations))
+		for i, location := range duplicate.Locations {
+			locations[i] = location.String()
+		}
+		md.WriteString("|" + duplicate.Kind + "|" + duplicate.Address + "|" + tw.Title(duplicate.Environment) + "|" + strings.Join(locations, "<br>") + "|\n")
+	}
+	md.WriteString("\n")
+
+	return md.String()
+}
+
 // isResourceExistenceDiff はリソース存在差分かどうかを判定する
 // リソース存在差分は、リソースの存在自体が差分として検出される場合
-func isResourceExistenceDiff(resource, value string) bool {
-	// boolean値（true/false）で、かつリソース名が適切な形式の場合のみリソース存在差分として扱う
-	// local.*, var.*, output.* のような設定値は除外
-	return (value == "true" || value == "false" || value == "") &&
-		   strings.Contains(resource, ".") &&
-		   !strings.HasPrefix(resource, "local.") &&
-		   !strings.HasPrefix(resource, "var.") &&
-		   !strings.HasPrefix(resource, "output.")
+func isResourceExistenceDiff(resourceType, value string) bool {
+	// boolean値（true/false）で、かつブロックの種類が設定値（local, variable, output）でない場合のみリソース存在差分として扱う
+	if value != "true" && value != "false" && value != "" {
+		return false
+	}
+	switch resourceType {
+	case types.KindLocal, types.KindVariable, types.KindOutput, types.KindTerraform, types.KindTerragrunt:
+		return false
+	}
+	return true
 }
 
 // buildHierarchicalMarkdownTable は階層化されたMarkdownテーブルを生成する
@@ -291,11 +493,14 @@ func (r *ResultReporter) buildHierarchicalMarkdownTable(rows []types.TableRow, e
 func (r *ResultReporter) convertToGroupedRows(rows []types.TableRow) []types.GroupedTableRow {
 	grouped := make([]types.GroupedTableRow, 0, len(rows))
 
-	// リソースタイプとリソース名でソート
+	// 重要度の高い順、リソースタイプとリソース名でソート
 	sort.Slice(rows, func(i, j int) bool {
 		typeA, nameA := r.parseResourceName(rows[i].Resource)
 		typeB, nameB := r.parseResourceName(rows[j].Resource)
 
+		if rankA, rankB := types.SeverityRank(rows[i].Severity), types.SeverityRank(rows[j].Severity); rankA != rankB {
+			return rankA > rankB
+		}
 		if typeA != typeB {
 			return typeA < typeB
 		}
@@ -305,7 +510,7 @@ func (r *ResultReporter) convertToGroupedRows(rows []types.TableRow) []types.Gro
 		return rows[i].Path < rows[j].Path
 	})
 
-	var prevType, prevName string
+	var prevType, prevName, prevSeverity string
 	for _, row := range rows {
 		resourceType, resourceName := r.parseResourceName(row.Resource)
 
@@ -315,40 +520,25 @@ func (r *ResultReporter) convertToGroupedRows(rows []types.TableRow) []types.Gro
 			Path:              row.Path,
 			Values:            row.Values,
 			Comment:           row.Comment,
-			IsFirstInGroup:    resourceType != prevType,
-			IsFirstInResource: resourceType != prevType || resourceName != prevName,
+			Severity:          row.Severity,
+			IsFirstInGroup:    resourceType != prevType || row.Severity != prevSeverity,
+			IsFirstInResource: resourceType != prevType || resourceName != prevName || row.Severity != prevSeverity,
 		}
 
 		grouped = append(grouped, groupedRow)
-		prevType, prevName = resourceType, resourceName
+		prevType, prevName, prevSeverity = resourceType, resourceName, row.Severity
 	}
 
 	return grouped
 }
 
-// parseResourceName はリソース名をタイプと名前に分割する
+// parseResourceName はリソースのアドレスを種類（resource, data, module等）と表示名に分割する
 func (r *ResultReporter) parseResourceName(resource string) (string, string) {
-	// local, output, variable, data等の特殊なケースを処理
-	if after, found := strings.CutPrefix(resource, "local."); found {
-		return "local", after
-	}
-	if after, found := strings.CutPrefix(resource, "output."); found {
-		return "output", after
-	}
-	if after, found := strings.CutPrefix(resource, "var."); found {
-		return "variable", after
-	}
-	if after, found := strings.CutPrefix(resource, "data."); found {
-		// data.aws_ami -> type: data, name: aws_ami
-		return "data", after
+	address, err := types.ParseAddress(resource)
+	if err != nil {
+		return resource, ""
 	}
-
-	// 通常のリソース（aws_instance.web -> type: resource, name: aws_instance.web）
-	parts := strings.SplitN(resource, ".", 2)
-	if len(parts) >= 2 {
-		return "resource", resource
-	}
-	return resource, ""
+	return address.Kind, address.DisplayName()
 }
 
 // buildGroupedMarkdownTable は階層化されたデータでMarkdownテーブルを生成する
@@ -358,9 +548,21 @@ func (r *ResultReporter) buildGroupedMarkdownTable(rows []types.GroupedTableRow,
 		tablewriter.WithRenderer(renderer.NewMarkdown()),
 	)
 
+	// 重要度が割り当てられた差分がある場合のみ重要度カラムを表示
+	includeSeverity := false
+	for _, row := range rows {
+		if row.Severity != "" {
+			includeSeverity = true
+			break
+		}
+	}
+
 	// ヘッダー設定
 	headers := []string{"リソースタイプ", "リソース名", "属性パス"}
 	headers = append(headers, envNames...)
+	if includeSeverity {
+		headers = append(headers, "重要度")
+	}
 	if includeComment {
 		headers = append(headers, "理由")
 	}
@@ -395,7 +597,7 @@ func (r *ResultReporter) buildGroupedMarkdownTable(rows []types.GroupedTableRow,
 			value := row.Values[env]
 
 			// リソース存在差分の場合のみ、boolean値をアイコンに変換
-			if row.Path == "" && isResourceExistenceDiff(row.ResourceType+"."+row.ResourceName, value) {
+			if row.Path == "" && isResourceExistenceDiff(row.ResourceType, value) {
 				if value == "" {
 					value = "false"
 				}
@@ -414,6 +616,14 @@ func (r *ResultReporter) buildGroupedMarkdownTable(rows []types.GroupedTableRow,
 			rowData = append(rowData, value)
 		}
 
+		if includeSeverity {
+			severity, exists := severityLabels[row.Severity]
+			if !exists {
+				severity = "-"
+			}
+			rowData = append(rowData, severity)
+		}
+
 		if includeComment {
 			comment := row.Comment
 			if comment == "" {
@@ -6,8 +6,11 @@ import (
 	"path/filepath"
 	"sort"
 
+	"github.com/Mkamono/tfspec/app/baseline"
+	"github.com/Mkamono/tfspec/app/cache"
 	"github.com/Mkamono/tfspec/app/config"
 	"github.com/Mkamono/tfspec/app/differ"
+	"github.com/Mkamono/tfspec/app/loader"
 	"github.com/Mkamono/tfspec/app/parser"
 	"github.com/Mkamono/tfspec/app/types"
 	"github.com/Mkamono/tfspec/app/interfaces"
@@ -19,12 +22,16 @@ import (
 // AnalyzerService は分析処理を担当する
 type AnalyzerService struct {
 	parser *parser.HCLParser
+	loader *loader.JSONLoader
 	differ *differ.HCLDiffer
+	jobs   int // 環境を並行して解析する数
 }
 
 func NewAnalyzerService() *AnalyzerService {
 	return &AnalyzerService{
 		parser: parser.NewHCLParser(),
+		loader: loader.NewJSONLoader(),
+		jobs:   1,
 	}
 }
 
@@ -36,14 +43,60 @@ func (s *AnalyzerService) Analyze(config *config.Config) (*interfaces.AnalysisRe
 		return nil, err
 	}
 
+	// 重要度ルールを読み込み
+	severityMatcher, err := s.loadSeverityRules(config.TfspecDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// 重要度ルールがない場合は重要度が割り当てられないため、--fail-on severity>= は判定できない
+	if config.FailOn != nil && config.FailOn.MinSeverity != "" && !severityMatcher.HasRules() {
+		return nil, fmt.Errorf("--fail-on severity>=%s には重要度ルールが必要です\n"+
+			"ヒント: .tfspec/.tfspecseverity に重要度ルールを記述してください", config.FailOn.MinSeverity)
+	}
+
 	// Differを初期化
 	s.differ = differ.NewHCLDiffer(ignoreRules)
+	s.differ.SetBaseEnv(config.BaseEnv)
+	s.jobs = config.Jobs
+
+	// ファイルごとの解析結果のキャッシュ（.tfspecディレクトリがある場合のみ）
+	var store *cache.Store
+	if !config.NoCache && config.TfspecDir != "" {
+		store = cache.NewStore(filepath.Join(config.TfspecDir, "cache"))
+		defer store.Prune()
+	}
+	s.parser.SetCache(store)
 
-	// 環境をパース
-	envResources, err := s.parseEnvironments(config.EnvDirs)
+	// 環境をパース（state / planファイル指定時はそれらを読み込む）
+	var envResources map[string]*types.EnvResources
+	var pendingChanges map[string][]*types.PendingChange
+	if len(config.StateFiles) > 0 {
+		envResources, err = s.loadStates(config.StateFiles)
+	} else if len(config.PlanFiles) > 0 {
+		envResources, pendingChanges, err = s.loadPlans(config.PlanFiles)
+	} else {
+		envResources, err = s.parseEnvironments(config.EnvDirs, config.EnvNames)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if config.Verbose && store != nil {
+		hits, misses := store.Stats()
+		fmt.Printf("📦 解析結果のキャッシュ: %d件を再利用、%d件を解析\n", hits, misses)
+	}
+
+	// 環境内で重複した定義はどれを比較すべきか決められないため、全環境の比較対象から除外する
+	duplicates := excludeDuplicates(envResources)
+
+	// 環境グループ内の比較（グループ間はグループの代表環境で比較する）
+	var groupResults []*interfaces.GroupResult
+	if len(config.Groups) > 0 {
+		groupResults, envResources, err = s.compareGroups(config.TfspecDir, config.Groups, envResources)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// 差分を検出
 	diffs, err := s.differ.Compare(envResources)
@@ -54,17 +107,139 @@ func (s *AnalyzerService) Analyze(config *config.Config) (*interfaces.AnalysisRe
 	// 警告を表示
 	s.displayIgnoreWarnings()
 
-	// 環境名を抽出
-	envNames := s.extractEnvNames(envResources)
+	// 基準refとの比較（新規・変更・解消された差分のみを残す）
+	var resolvedDiffs []*types.DiffResult
+	if config.BaseRef != "" {
+		baseDiffs, err := s.analyzeBaseRef(config.BaseRef, config.EnvDirs, config.EnvNames, config.BaseEnv, ignoreRules)
+		if err != nil {
+			return nil, err
+		}
+		diffs, resolvedDiffs = differ.CompareWithBase(baseDiffs, diffs)
+	}
+
+	// ベースラインの書き込み・照合（グループ内の差分も対象）
+	if config.WriteBaseline != "" {
+		entryCount, err := baseline.Write(config.WriteBaseline, appendGroupDiffs(diffs, groupResults))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("📄 ベースラインを出力しました: %s（%d件）\n", config.WriteBaseline, entryCount)
+	}
+
+	var baselineDiffs []*types.DiffResult
+	var staleBaselineEntries []*types.BaselineEntry
+	if config.BaselineFile != "" {
+		loadedBaseline, err := baseline.Load(config.BaselineFile)
+		if err != nil {
+			return nil, err
+		}
+		var remaining []*types.DiffResult
+		remaining, baselineDiffs, staleBaselineEntries = loadedBaseline.Apply(appendGroupDiffs(diffs, groupResults))
+		diffs = keepDiffs(diffs, remaining)
+		for _, groupResult := range groupResults {
+			groupResult.Diffs = keepDiffs(groupResult.Diffs, remaining)
+		}
+	}
+
+	// 重要度を割り当て
+	severityMatcher.Assign(diffs)
+	severityMatcher.Assign(resolvedDiffs)
+	severityMatcher.Assign(baselineDiffs)
+	for _, groupResult := range groupResults {
+		severityMatcher.Assign(groupResult.Diffs)
+	}
+
+	// 環境名を抽出（比較元の環境が先頭）
+	envNames, err := s.extractEnvNames(envResources, config.BaseEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	staleRules, expiredRules := s.differ.GetStaleRules(), s.differ.GetExpiredRules()
+	for _, groupResult := range groupResults {
+		for _, rule := range groupResult.StaleRules {
+			staleRules = append(staleRules, groupResult.Name+": "+rule)
+		}
+		for _, rule := range groupResult.ExpiredRules {
+			expiredRules = append(expiredRules, groupResult.Name+": "+rule)
+		}
+	}
 
 	return &interfaces.AnalysisResult{
 		Diffs:        diffs,
 		EnvResources: envResources,
 		RuleComments: ruleComments,
 		EnvNames:     envNames,
+
+		PendingChanges: pendingChanges,
+		BaseRef:        config.BaseRef,
+		ResolvedDiffs:  resolvedDiffs,
+
+		BaselineFile:         config.BaselineFile,
+		BaselineDiffs:        baselineDiffs,
+		StaleBaselineEntries: staleBaselineEntries,
+
+		StaleRules:   staleRules,
+		ExpiredRules: expiredRules,
+
+		Groups: groupResults,
+
+		Duplicates: duplicates,
 	}, nil
 }
 
+// excludeDuplicates は環境ごとに重複した定義を検出し、そのアドレスを全ての環境から取り除く
+// 重複の一覧は環境名、アドレスの順に返す
+func excludeDuplicates(envResources map[string]*types.EnvResources) []*types.DuplicateDefinition {
+	envNames := make([]string, 0, len(envResources))
+	for envName := range envResources {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+
+	var duplicates []*types.DuplicateDefinition
+	addresses := make(map[string]bool)
+	for _, envName := range envNames {
+		for _, duplicate := range envResources[envName].Duplicates() {
+			duplicate.Environment = envName
+			duplicates = append(duplicates, duplicate)
+			addresses[duplicate.Address] = true
+		}
+	}
+
+	if len(addresses) > 0 {
+		for _, resources := range envResources {
+			resources.RemoveDefinitions(addresses)
+		}
+	}
+	return duplicates
+}
+
+// appendGroupDiffs はグループ間の差分とグループ内の差分を結合する
+func appendGroupDiffs(diffs []*types.DiffResult, groupResults []*interfaces.GroupResult) []*types.DiffResult {
+	allDiffs := append([]*types.DiffResult{}, diffs...)
+	for _, groupResult := range groupResults {
+		allDiffs = append(allDiffs, groupResult.Diffs...)
+	}
+	return allDiffs
+}
+
+// keepDiffs は差分のうち remaining に含まれるものだけを返す
+func keepDiffs(diffs, remaining []*types.DiffResult) []*types.DiffResult {
+	keep := make(map[*types.DiffResult]bool, len(remaining))
+	for _, diff := range remaining {
+		keep[diff] = true
+	}
+
+	var kept []*types.DiffResult
+	for _, diff := range diffs {
+		if keep[diff] {
+			kept = append(kept, diff)
+		}
+	}
+	return kept
+}
+
 // loadIgnoreRules は無視ルールとコメントを読み込む
 func (s *AnalyzerService) loadIgnoreRules(tfspecDir string) ([]string, map[string]string, error) {
 	ignoreRules, err := parser.LoadIgnoreRules(tfspecDir)
@@ -86,34 +261,49 @@ func (s *AnalyzerService) loadIgnoreRules(tfspecDir string) ([]string, map[strin
 	return ignoreRules, ruleComments, nil
 }
 
-// parseEnvironments は全環境のリソースを解析する
-func (s *AnalyzerService) parseEnvironments(envDirs []string) (map[string]*types.EnvResources, error) {
+// loadSeverityRules は重要度ルールを読み込む
+func (s *AnalyzerService) loadSeverityRules(tfspecDir string) (*differ.SeverityMatcher, error) {
+	severityRules, err := parser.LoadSeverityRules(tfspecDir)
+	if err != nil {
+		return nil, fmt.Errorf(".tfspecseverityファイルの読み込みに失敗しました: %w\n"+
+			"ヒント: .tfspec/.tfspecseverity ファイルを確認してください", err)
+	}
+
+	severityMatcher := differ.NewSeverityMatcher(severityRules)
+	if severityMatcher.HasRules() {
+		fmt.Printf("重要度ルールを読み込みました: %d件\n", len(severityRules))
+	}
+	for _, warning := range severityMatcher.GetWarnings() {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	return severityMatcher, nil
+}
+
+// parseEnvironments は全環境のリソースを解析する（envNames は環境ディレクトリ -> 環境名）
+// 環境は最大jobs個ずつ並行して解析し、結果・エラーは環境ディレクトリの順に集約する
+func (s *AnalyzerService) parseEnvironments(envDirs []string, envNames map[string]string) (map[string]*types.EnvResources, error) {
 	envResources := make(map[string]*types.EnvResources)
 	var skippedFiles []string
 
-	for _, envDir := range envDirs {
-		envName := filepath.Base(envDir)
+	parsed := make([]*types.EnvResources, len(envDirs))
+	errs := make([]error, len(envDirs))
+	runParallel(len(envDirs), s.jobs, func(i int) {
+		parsed[i], errs[i] = s.parseEnvironment(envDirs[i])
+	})
 
-		// 環境ディレクトリ内の全ての.tf/.hclファイルを探す
-		terraformFiles, err := s.findTerraformFiles(envDir)
-		if err != nil {
-			return nil, fmt.Errorf("Terraformファイルの検索に失敗しました: %w", err)
+	for i, envDir := range envDirs {
+		if errs[i] != nil {
+			return nil, errs[i]
 		}
 
-		if len(terraformFiles) == 0 {
+		if parsed[i] == nil {
 			hclFile := filepath.Join(envDir, "main.hcl")
 			tfFile := filepath.Join(envDir, "main.tf")
 			skippedFiles = append(skippedFiles, hclFile+" または "+tfFile+" (または他の.tf/.hclファイル)")
 			continue
 		}
 
-		envResource, err := s.parser.ParseMultipleFiles(terraformFiles)
-		if err != nil {
-			return nil, fmt.Errorf("環境ファイルの解析に失敗しました:\n  ファイル: %v\n  エラー: %w\n"+
-				"ヒント: HCL構文を確認してください", terraformFiles, err)
-		}
-
-		envResources[envName] = envResource
+		envResources[envNames[envDir]] = parsed[i]
 	}
 
 	if len(skippedFiles) > 0 {
@@ -128,6 +318,126 @@ func (s *AnalyzerService) parseEnvironments(envDirs []string) (map[string]*types
 	return envResources, nil
 }
 
+// parseEnvironment は1つの環境のリソースを解析する（.tf/.hclファイルがない場合はnil）
+func (s *AnalyzerService) parseEnvironment(envDir string) (*types.EnvResources, error) {
+	// 環境ディレクトリ内の全ての.tf/.hclファイルを探す
+	terraformFiles, err := s.findTerraformFiles(envDir)
+	if err != nil {
+		return nil, fmt.Errorf("Terraformファイルの検索に失敗しました: %w", err)
+	}
+
+	if len(terraformFiles) == 0 {
+		return nil, nil
+	}
+
+	envResource, err := s.parser.ParseMultipleFiles(terraformFiles)
+	if err != nil {
+		return nil, fmt.Errorf("環境ファイルの解析に失敗しました:\n  ファイル: %v\n  エラー: %w\n"+
+			"ヒント: HCL構文を確認してください", terraformFiles, err)
+	}
+
+	return envResource, nil
+}
+
+// analyzeBaseRef は基準ref時点の環境ファイルをgitリポジトリから取得し、同じ無視ルールで差分を検出する
+func (s *AnalyzerService) analyzeBaseRef(baseRef string, envDirs []string, envNames map[string]string, baseEnv string, ignoreRules []string) ([]*types.DiffResult, error) {
+	repo, baseDir, cleanup, err := exportRevision(baseRef)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	// 環境ディレクトリを基準ref時点の同じ位置に対応付ける（基準refに存在しない環境は除外）
+	var baseEnvDirs []string
+	baseEnvNames := make(map[string]string)
+	for _, envDir := range envDirs {
+		relPath, err := repo.RelativePath(envDir)
+		if err != nil {
+			return nil, err
+		}
+
+		baseEnvDir := filepath.Join(baseDir, relPath)
+		if _, err := os.Stat(baseEnvDir); err != nil {
+			fmt.Printf("⚠️  環境 %s は %s に存在しません\n", envNames[envDir], baseRef)
+			continue
+		}
+		baseEnvDirs = append(baseEnvDirs, baseEnvDir)
+		baseEnvNames[baseEnvDir] = envNames[envDir]
+	}
+
+	fmt.Printf("基準ref: %s\n", baseRef)
+	if len(baseEnvDirs) == 0 {
+		return nil, nil
+	}
+
+	// 一時ディレクトリに展開したファイルの解析結果は再利用できないため、キャッシュを設定しない別のパーサで解析する
+	baseAnalyzer := &AnalyzerService{parser: parser.NewHCLParser(), jobs: s.jobs}
+	baseEnvResources, err := baseAnalyzer.parseEnvironments(baseEnvDirs, baseEnvNames)
+	if err != nil {
+		return nil, fmt.Errorf("%s 時点の環境の解析に失敗しました: %w", baseRef, err)
+	}
+	// 現在の環境と同じく、重複した定義は比較対象から除外する
+	excludeDuplicates(baseEnvResources)
+
+	baseDiffer := differ.NewHCLDiffer(ignoreRules)
+	baseDiffer.SetBaseEnv(baseEnv)
+	baseDiffs, err := baseDiffer.Compare(baseEnvResources)
+	if err != nil {
+		return nil, fmt.Errorf("%s 時点の差分検出に失敗しました: %w", baseRef, err)
+	}
+	return baseDiffs, nil
+}
+
+// loadStates は全環境のstateファイルを読み込む
+func (s *AnalyzerService) loadStates(stateFiles map[string]string) (map[string]*types.EnvResources, error) {
+	envResources := make(map[string]*types.EnvResources)
+
+	// 複数のファイルを読み込めない場合に同じエラーを報告するよう、環境名の順に読み込む
+	envNames := make([]string, 0, len(stateFiles))
+	for envName := range stateFiles {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+
+	for _, envName := range envNames {
+		stateFile := stateFiles[envName]
+		envResource, err := s.loader.LoadState(stateFile)
+		if err != nil {
+			return nil, fmt.Errorf("stateファイルの読み込みに失敗しました:\n  環境: %s\n  ファイル: %s\n  エラー: %w", envName, stateFile, err)
+		}
+		envResources[envName] = envResource
+	}
+
+	return envResources, nil
+}
+
+// loadPlans は全環境のplanファイルを読み込み、未適用の変更を環境ごとに返す
+func (s *AnalyzerService) loadPlans(planFiles map[string]string) (map[string]*types.EnvResources, map[string][]*types.PendingChange, error) {
+	envResources := make(map[string]*types.EnvResources)
+	pendingChanges := make(map[string][]*types.PendingChange)
+
+	// 複数のファイルを読み込めない場合に同じエラーを報告するよう、環境名の順に読み込む
+	envNames := make([]string, 0, len(planFiles))
+	for envName := range planFiles {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+
+	for _, envName := range envNames {
+		planFile := planFiles[envName]
+		envResource, changes, err := s.loader.LoadPlan(planFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("planファイルの読み込みに失敗しました:\n  環境: %s\n  ファイル: %s\n  エラー: %w", envName, planFile, err)
+		}
+		envResources[envName] = envResource
+		if len(changes) > 0 {
+			pendingChanges[envName] = changes
+		}
+	}
+
+	return envResources, pendingChanges, nil
+}
+
 // displayIgnoreWarnings は無視ルールの警告を表示する
 func (s *AnalyzerService) displayIgnoreWarnings() {
 	warnings := s.differ.GetIgnoreWarnings()
@@ -164,12 +474,11 @@ func (s *AnalyzerService) findTerraformFiles(dir string) ([]string, error) {
 	return terraformFiles, nil
 }
 
-// extractEnvNames は環境名リストを抽出してソートする
-func (s *AnalyzerService) extractEnvNames(envResources map[string]*types.EnvResources) []string {
+// extractEnvNames は環境名リストを抽出してソートし、比較元の環境を先頭にする
+func (s *AnalyzerService) extractEnvNames(envResources map[string]*types.EnvResources, baseEnv string) ([]string, error) {
 	envNames := make([]string, 0, len(envResources))
 	for envName := range envResources {
 		envNames = append(envNames, envName)
 	}
-	sort.Strings(envNames)
-	return envNames
+	return differ.OrderEnvNames(envNames, baseEnv)
 }
\ No newline at end of file
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Mkamono/tfspec/app/differ"
+	"github.com/Mkamono/tfspec/app/parser"
+	"github.com/Mkamono/tfspec/app/types"
+)
+
+// ベンチマーク用に生成する環境の数・環境あたりのリソース数（TFSPEC_BENCH_ENVS / TFSPEC_BENCH_RESOURCES で変更できる）
+// env2 は全リソースのinstance_typeが異なり、他の環境は同じ構成になる
+var (
+	benchEnvs      = benchSize("TFSPEC_BENCH_ENVS", 3)
+	benchResources = benchSize("TFSPEC_BENCH_RESOURCES", 2000)
+)
+
+// BenchmarkParseMultipleFiles は全環境の解析を --jobs 1 と --jobs <環境数>（全環境を並行して解析）で比較する
+// 並行して解析する効果はCPU数が環境数以上の場合に最も大きくなる
+func BenchmarkParseMultipleFiles(b *testing.B) {
+	envDirs, envNames := writeBenchEnvironments(b)
+
+	for _, jobs := range []int{1, benchEnvs} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// 解析済みファイルの再利用を避けるため、毎回新しいパーサで解析する
+				s := &AnalyzerService{parser: parser.NewHCLParser(), jobs: jobs}
+				envResources, err := s.parseEnvironments(envDirs, envNames)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(envResources) != benchEnvs {
+					b.Fatalf("解析した環境の数が %d です（期待値: %d）", len(envResources), benchEnvs)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCompare は索引でリソースを対応付けた環境間の比較を計測する
+func BenchmarkCompare(b *testing.B) {
+	envResources := parseBenchEnvironments(b)
+
+	for i := 0; i < b.N; i++ {
+		diffs, err := differ.NewHCLDiffer(nil).Compare(envResources)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(diffs) != benchResources {
+			b.Fatalf("差分の数が %d です（期待値: env2のinstance_typeの %d 件）", len(diffs), benchResources)
+		}
+	}
+}
+
+// BenchmarkResourceIndex は比較元の全リソースを比較先から引く処理を、索引と索引導入前の線形探索で比較する
+func BenchmarkResourceIndex(b *testing.B) {
+	envResources := parseBenchEnvironments(b)
+	base, target := envResources["env1"], envResources["env2"]
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			// 索引の構築も計測に含めるため、毎回索引のない構成から引く
+			index := (&types.EnvResources{Resources: target.Resources}).Index()
+			found := 0
+			for _, resource := range base.Resources {
+				if index.Resource(resource.Address()) != nil {
+					found++
+				}
+			}
+			if found != len(base.Resources) {
+				b.Fatalf("見つかったリソースが %d 件です（期待値: %d）", found, len(base.Resources))
+			}
+		}
+	})
+
+	b.Run("linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			found := 0
+			for _, resource := range base.Resources {
+				for _, candidate := range target.Resources {
+					if candidate.Type == resource.Type && candidate.Name == resource.Name && candidate.Key == resource.Key {
+						found++
+						break
+					}
+				}
+			}
+			if found != len(base.Resources) {
+				b.Fatalf("見つかったリソースが %d 件です（期待値: %d）", found, len(base.Resources))
+			}
+		}
+	})
+}
+
+// parseBenchEnvironments はベンチマーク用の環境を生成して解析する
+func parseBenchEnvironments(b *testing.B) map[string]*types.EnvResources {
+	envDirs, envNames := writeBenchEnvironments(b)
+	s := &AnalyzerService{parser: parser.NewHCLParser(), jobs: runtime.NumCPU()}
+	envResources, err := s.parseEnvironments(envDirs, envNames)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	return envResources
+}
+
+// writeBenchEnvironments は一時ディレクトリに env1〜envN を生成し、環境ディレクトリと環境名を返す
+func writeBenchEnvironments(b *testing.B) ([]string, map[string]string) {
+	b.Helper()
+	root := b.TempDir()
+
+	var envDirs []string
+	envNames := make(map[string]string)
+	for env := 1; env <= benchEnvs; env++ {
+		instanceType := "t3.micro"
+		if env == 2 {
+			instanceType = "t3.large"
+		}
+
+		var content strings.Builder
+		for i := 1; i <= benchResources; i++ {
+			fmt.Fprintf(&content, "resource \"aws_instance\" \"web_%d\" {\n  ami           = \"ami-%d\"\n  instance_type = %q\n\n  tags = {\n    Name = \"web-%d\"\n  }\n}\n\n", i, i, instanceType, i)
+			if i%10 == 0 {
+				fmt.Fprintf(&content, "data \"aws_ami\" \"image_%d\" {\n  owners = [\"self\"]\n}\n\n", i)
+			}
+		}
+
+		envDir := filepath.Join(root, fmt.Sprintf("env%d", env))
+		if err := os.MkdirAll(envDir, 0o755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(envDir, "main.tf"), []byte(content.String()), 0o644); err != nil {
+			b.Fatal(err)
+		}
+		envDirs = append(envDirs, envDir)
+		envNames[envDir] = filepath.Base(envDir)
+	}
+
+	b.ResetTimer()
+	return envDirs, envNames
+}
+
+// benchSize は環境変数で指定されたサイズを返す（未指定・不正な場合はデフォルト値）
+func benchSize(name string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Mkamono/tfspec/app/config"
+	"github.com/Mkamono/tfspec/app/interfaces"
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// 終了コード（0は成功）
+const (
+	ExitCodeDrift          = 1 // 構成ドリフトを検出した
+	ExitCodeError          = 2 // 解析・設定のエラー
+	ExitCodeStaleRules     = 3 // 実際のリソース構成に存在しない無視ルールがある
+	ExitCodeExpiredRules   = 4 // 期限切れの無視ルールがある
+	ExitCodePendingChanges = 5 // planに未適用の変更がある
+)
+
+// ExitError は終了コードを伴うエラー
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// evaluateFailOn は --fail-on の条件に従って検出結果を評価し、該当する場合はExitErrorを返す
+// 環境内で重複した定義は --fail-on によらずエラーとする
+// 複数の条件に該当する場合は 重複した定義、構成ドリフト、期限切れルール、存在しないルール、未適用の変更 の順に優先する
+func evaluateFailOn(failOn *config.FailOn, result *interfaces.AnalysisResult, pendingCount int) error {
+	if len(result.Duplicates) > 0 {
+		return &ExitError{Code: ExitCodeError, Err: fmt.Errorf("%d件の定義が環境内で重複しています", len(result.Duplicates))}
+	}
+	if driftCount := countFailingDrift(failOn, result.AllDiffs()); driftCount > 0 {
+		return &ExitError{Code: ExitCodeDrift, Err: fmt.Errorf("%d件の構成ドリフトが検出されました", driftCount)}
+	}
+	if failOn.ExpiredRules && len(result.ExpiredRules) > 0 {
+		return &ExitError{Code: ExitCodeExpiredRules, Err: fmt.Errorf("%d件の無視ルールが期限切れです: %v", len(result.ExpiredRules), result.ExpiredRules)}
+	}
+	if failOn.StaleRules && len(result.StaleRules) > 0 {
+		return &ExitError{Code: ExitCodeStaleRules, Err: fmt.Errorf("%d件の無視ルールが実際のリソース構成に存在しません: %v", len(result.StaleRules), result.StaleRules)}
+	}
+	if failOn.PendingChanges && pendingCount > 0 {
+		return &ExitError{Code: ExitCodePendingChanges, Err: fmt.Errorf("%d件の未適用の変更がplanに含まれています", pendingCount)}
+	}
+	return nil
+}
+
+// countFailingDrift は --fail-on の条件に該当する構成ドリフトの件数を返す
+func countFailingDrift(failOn *config.FailOn, diffs []*types.DiffResult) int {
+	var count int
+	for _, diff := range diffs {
+		if diff.IsIgnored {
+			continue
+		}
+
+		switch {
+		case failOn.Drift:
+		case failOn.ExistenceOnly && isExistenceDiff(diff):
+		case failOn.MinSeverity != "" && types.SeverityRank(diff.Severity) >= types.SeverityRank(failOn.MinSeverity):
+		default:
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// isExistenceDiff は存在差分（リソース・モジュール等の有無）かどうかを判定する
+func isExistenceDiff(diff *types.DiffResult) bool {
+	return diff.Path == "" && diff.Expected.Type() == cty.Bool && diff.Actual.Type() == cty.Bool
+}
+
+// exitCodePriority は複数の条件に該当した場合の終了コードの優先順位
+var exitCodePriority = []int{ExitCodeError, ExitCodeDrift, ExitCodeExpiredRules, ExitCodeStaleRules, ExitCodePendingChanges}
+
+// mostSevereExitError は複数のスタックの評価結果から最も優先度の高い終了コードのエラーを返す（該当なしの場合はnil）
+func mostSevereExitError(failures []*ExitError) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	var messages []string
+	for _, failure := range failures {
+		messages = append(messages, failure.Err.Error())
+	}
+
+	for _, code := range exitCodePriority {
+		for _, failure := range failures {
+			if failure.Code == code {
+				return &ExitError{Code: code, Err: errors.New(strings.Join(messages, "\n"))}
+			}
+		}
+	}
+	return &ExitError{Code: failures[0].Code, Err: errors.New(strings.Join(messages, "\n"))}
+}
+
+// countPendingChanges はplanに含まれる未適用の変更の件数を返す
+func countPendingChanges(result *interfaces.AnalysisResult) int {
+	var pendingCount int
+	for _, changes := range result.PendingChanges {
+		pendingCount += len(changes)
+	}
+	return pendingCount
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/Mkamono/tfspec/app/config"
+	"github.com/Mkamono/tfspec/app/differ"
+	"github.com/Mkamono/tfspec/app/interfaces"
+	"github.com/Mkamono/tfspec/app/parser"
+	"github.com/Mkamono/tfspec/app/types"
+)
+
+// compareGroups は環境グループ内の環境を比較し、グループ間の比較に使う環境の構成を返す
+// グループ間の比較ではグループの1つ目の環境をグループの代表とし、グループ名を環境名とする（グループに属さない環境はそのまま）
+func (s *AnalyzerService) compareGroups(tfspecDir string, groups []*config.Group, envResources map[string]*types.EnvResources) ([]*interfaces.GroupResult, map[string]*types.EnvResources, error) {
+	groupedEnvResources := make(map[string]*types.EnvResources)
+	grouped := make(map[string]bool)
+	var groupResults []*interfaces.GroupResult
+
+	for _, group := range groups {
+		members := make(map[string]*types.EnvResources)
+		for _, envName := range group.Envs {
+			envResource, exists := envResources[envName]
+			if !exists {
+				return nil, nil, fmt.Errorf("環境グループ '%s' の環境 '%s' が見つかりません\n"+
+					"ヒント: 対象環境 %v のいずれかを指定してください", group.Name, envName, sortedEnvNames(envResources))
+			}
+			members[envName] = envResource
+			grouped[envName] = true
+		}
+		groupedEnvResources[group.Name] = envResources[group.Envs[0]]
+
+		groupResult, err := s.compareGroupMembers(tfspecDir, group, members)
+		if err != nil {
+			return nil, nil, err
+		}
+		groupResults = append(groupResults, groupResult)
+	}
+
+	for envName, envResource := range envResources {
+		if grouped[envName] {
+			continue
+		}
+		if _, exists := groupedEnvResources[envName]; exists {
+			return nil, nil, fmt.Errorf("環境グループ名 '%s' が環境名と重複しています", envName)
+		}
+		groupedEnvResources[envName] = envResource
+	}
+
+	return groupResults, groupedEnvResources, nil
+}
+
+// compareGroupMembers はグループ専用の無視ルール（.tfspec/groups/<グループ名>/.tfspecignore）でグループ内の環境を比較する
+func (s *AnalyzerService) compareGroupMembers(tfspecDir string, group *config.Group, members map[string]*types.EnvResources) (*interfaces.GroupResult, error) {
+	var groupTfspecDir string
+	if tfspecDir != "" {
+		groupTfspecDir = filepath.Join(tfspecDir, "groups", group.Name)
+	}
+
+	ignoreRules, err := parser.LoadIgnoreRules(groupTfspecDir)
+	if err != nil {
+		return nil, fmt.Errorf("環境グループ '%s' の.tfspecignoreファイルの読み込みに失敗しました: %w", group.Name, err)
+	}
+	ruleComments, err := parser.LoadIgnoreRulesWithComments(groupTfspecDir)
+	if err != nil {
+		return nil, fmt.Errorf("環境グループ '%s' の.tfspecignoreのコメント情報の読み込みに失敗しました: %w", group.Name, err)
+	}
+
+	groupDiffer := differ.NewHCLDiffer(ignoreRules)
+	groupDiffer.SetBaseEnv(group.Envs[0])
+	diffs, err := groupDiffer.Compare(members)
+	if err != nil {
+		return nil, fmt.Errorf("環境グループ '%s' の差分検出に失敗しました: %w", group.Name, err)
+	}
+
+	fmt.Printf("環境グループ %s: %v（無視ルール: %d件）\n", group.Name, group.Envs, len(ignoreRules))
+	for _, warning := range groupDiffer.GetIgnoreWarnings() {
+		fmt.Printf("⚠️  [%s] %s\n", group.Name, warning)
+	}
+
+	envNames, err := differ.OrderEnvNames(group.Envs, group.Envs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &interfaces.GroupResult{
+		Name:         group.Name,
+		EnvNames:     envNames,
+		Diffs:        diffs,
+		EnvResources: members,
+		RuleComments: ruleComments,
+		StaleRules:   groupDiffer.GetStaleRules(),
+		ExpiredRules: groupDiffer.GetExpiredRules(),
+	}, nil
+}
+
+// sortedEnvNames は環境名をソートして返す
+func sortedEnvNames(envResources map[string]*types.EnvResources) []string {
+	envNames := make([]string, 0, len(envResources))
+	for envName := range envResources {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+	return envNames
+}
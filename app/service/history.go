@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Mkamono/tfspec/app/config"
+	"github.com/Mkamono/tfspec/app/differ"
+	"github.com/Mkamono/tfspec/app/interfaces"
+	"github.com/Mkamono/tfspec/app/types"
+)
+
+// currentRevisionLabel は作業ツリーの構成を表す列名
+const currentRevisionLabel = "現在"
+
+// HistoryService は単一環境の構成の変更履歴の分析を担当する
+type HistoryService struct {
+	analyzer *AnalyzerService
+}
+
+func NewHistoryService() *HistoryService {
+	return &HistoryService{
+		analyzer: NewAnalyzerService(),
+	}
+}
+
+// Analyze は環境の指定ref時点と作業ツリーの構成を比較する
+func (s *HistoryService) Analyze(options *config.HistoryOptions) (*interfaces.HistoryResult, error) {
+	if info, err := os.Stat(options.EnvDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("環境ディレクトリが見つかりません: %s", options.EnvDir)
+	}
+
+	repo, revisionDir, cleanup, err := exportRevision(options.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	relPath, err := repo.RelativePath(options.EnvDir)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := s.parseEnvironment(options.EnvDir)
+	if err != nil {
+		return nil, err
+	}
+	if after == nil {
+		return nil, fmt.Errorf("環境ディレクトリに.tf/.hclファイルがありません: %s", options.EnvDir)
+	}
+
+	// 指定ref時点に環境が存在しない場合は、全ての構成が追加されたものとして扱う
+	before, err := s.parseEnvironment(filepath.Join(revisionDir, relPath))
+	if err != nil {
+		return nil, fmt.Errorf("%s 時点の環境の解析に失敗しました: %w", options.Since, err)
+	}
+	if before == nil {
+		fmt.Printf("⚠️  環境 %s は %s に存在しません\n", filepath.Base(options.EnvDir), options.Since)
+		before = &types.EnvResources{}
+	}
+
+	diffs := differ.NewHCLDiffer(nil).CompareRevisions(before, after, currentRevisionLabel)
+
+	return &interfaces.HistoryResult{
+		EnvName: filepath.Base(options.EnvDir),
+		Since:   options.Since,
+		Diffs:   diffs,
+		Columns: []string{options.Since, currentRevisionLabel},
+		EnvResources: map[string]*types.EnvResources{
+			options.Since:        before,
+			currentRevisionLabel: after,
+		},
+	}, nil
+}
+
+// parseEnvironment は環境ディレクトリを解析する（ディレクトリや.tf/.hclファイルがない場合はnilを返す）
+func (s *HistoryService) parseEnvironment(envDir string) (*types.EnvResources, error) {
+	if _, err := os.Stat(envDir); err != nil {
+		return nil, nil
+	}
+
+	terraformFiles, err := s.analyzer.findTerraformFiles(envDir)
+	if err != nil {
+		return nil, fmt.Errorf("Terraformファイルの検索に失敗しました: %w", err)
+	}
+	if len(terraformFiles) == 0 {
+		return nil, nil
+	}
+
+	envResources, err := s.analyzer.parser.ParseMultipleFiles(terraformFiles)
+	if err != nil {
+		return nil, fmt.Errorf("環境ファイルの解析に失敗しました:\n  ファイル: %v\n  エラー: %w\n"+
+			"ヒント: HCL構文を確認してください", terraformFiles, err)
+	}
+	return envResources, nil
+}
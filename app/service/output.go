@@ -5,24 +5,75 @@ import (
 	"os"
 	"strings"
 
+	"github.com/Mkamono/tfspec/app/differ"
+	"github.com/Mkamono/tfspec/app/parser"
 	"github.com/Mkamono/tfspec/app/reporter"
 	"github.com/Mkamono/tfspec/app/types"
 	"github.com/Mkamono/tfspec/app/interfaces"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // OutputService は結果出力を担当する
 type OutputService struct {
-	reporter *reporter.ResultReporter
+	reporter  *reporter.ResultReporter
+	formatter *parser.ValueFormatter
 }
 
 func NewOutputService() *OutputService {
 	return &OutputService{
-		reporter: reporter.NewResultReporter(),
+		reporter:  reporter.NewResultReporter(),
+		formatter: parser.NewValueFormatter(),
 	}
 }
 
 // OutputResults は結果を出力する
 func (s *OutputService) OutputResults(result *interfaces.AnalysisResult, outputFile string, outputFlag bool, maxValueLength int, trimCell bool) error {
+	markdownOutput := s.generateReport(result, maxValueLength, trimCell)
+
+	// コンソール出力
+	fmt.Print(markdownOutput)
+
+	// ファイル出力
+	if outputFlag {
+		if err := s.writeToFile(markdownOutput, outputFile); err != nil {
+			return err
+		}
+		fmt.Printf("📄 結果レポートを出力しました: %s\n", outputFile)
+	}
+
+	return nil
+}
+
+// WriteResults は結果をMarkdownファイルにのみ出力する（watchモード用）
+func (s *OutputService) WriteResults(result *interfaces.AnalysisResult, outputFile string, maxValueLength int, trimCell bool) error {
+	return s.writeToFile(s.generateReport(result, maxValueLength, trimCell), outputFile)
+}
+
+// OutputStackResults は全スタックの結果を1つのレポートに統合して出力する
+func (s *OutputService) OutputStackResults(results []*interfaces.StackResult, outputFile string, outputFlag bool, maxValueLength int, trimCell bool) error {
+	var markdownOutput strings.Builder
+	markdownOutput.WriteString("# Tfspec Check Results\n\n")
+	for _, stackResult := range results {
+		report := s.generateReport(stackResult.Result, maxValueLength, trimCell)
+		markdownOutput.WriteString(s.reporter.GenerateStackSection(stackResult.Name, report))
+	}
+
+	// コンソール出力
+	fmt.Print("\n" + markdownOutput.String())
+
+	// ファイル出力
+	if outputFlag {
+		if err := s.writeToFile(markdownOutput.String(), outputFile); err != nil {
+			return err
+		}
+		fmt.Printf("📄 結果レポートを出力しました: %s\n", outputFile)
+	}
+
+	return nil
+}
+
+// generateReport は分析結果のMarkdownレポートを生成する
+func (s *OutputService) generateReport(result *interfaces.AnalysisResult, maxValueLength int, trimCell bool) string {
 	markdownOutput := s.reporter.GenerateMarkdown(
 		result.Diffs,
 		result.EnvNames,
@@ -31,6 +82,26 @@ func (s *OutputService) OutputResults(result *interfaces.AnalysisResult, outputF
 		maxValueLength,
 		trimCell,
 	)
+	markdownOutput += s.reporter.GenerateDuplicatesMarkdown(result.Duplicates)
+	for _, group := range result.Groups {
+		markdownOutput += s.reporter.GenerateGroupMarkdown(group.Name, group.Diffs, group.EnvNames, group.RuleComments, group.EnvResources, maxValueLength, trimCell)
+	}
+	markdownOutput += s.reporter.GeneratePendingChangesMarkdown(result.PendingChanges, result.EnvNames)
+	markdownOutput += s.reporter.GenerateResolvedDiffsMarkdown(result.ResolvedDiffs, result.BaseRef)
+	markdownOutput += s.reporter.GenerateBaselineMarkdown(result.BaselineDiffs, result.StaleBaselineEntries, result.EnvNames, result.EnvResources)
+	return markdownOutput
+}
+
+// OutputHistory は変更履歴を出力する
+func (s *OutputService) OutputHistory(result *interfaces.HistoryResult, outputFile string, outputFlag bool, maxValueLength int, trimCell bool) error {
+	markdownOutput := s.reporter.GenerateHistoryMarkdown(
+		result.Diffs,
+		result.EnvName,
+		result.Columns,
+		result.EnvResources,
+		maxValueLength,
+		trimCell,
+	)
 
 	// コンソール出力
 	fmt.Print(markdownOutput)
@@ -40,9 +111,11 @@ func (s *OutputService) OutputResults(result *interfaces.AnalysisResult, outputF
 		if err := s.writeToFile(markdownOutput, outputFile); err != nil {
 			return err
 		}
-		fmt.Printf("📄 結果レポートを出力しました: %s\n", outputFile)
+		fmt.Printf("📄 変更履歴を出力しました: %s\n", outputFile)
 	}
 
+	fmt.Printf("\n=== サマリー ===\n")
+	fmt.Printf("%s からの変更: %d件\n", result.Since, len(result.Diffs))
 	return nil
 }
 
@@ -76,6 +149,141 @@ func (s *OutputService) PrintSummary(diffs []*types.DiffResult) (int, int) {
 	return ignoredCount, driftCount
 }
 
+// PrintPendingChanges はplanに含まれる未適用の変更の件数を出力する（plan比較時のみ）
+func (s *OutputService) PrintPendingChanges(pendingChanges map[string][]*types.PendingChange) int {
+	var pendingCount int
+	for _, changes := range pendingChanges {
+		pendingCount += len(changes)
+	}
+
+	if pendingCount > 0 {
+		fmt.Printf("未適用の変更: %d件\n", pendingCount)
+	}
+	return pendingCount
+}
+
+// PrintBaselineSummary はベースラインとの照合結果の件数を出力する（--baseline-file指定時のみ）
+func (s *OutputService) PrintBaselineSummary(result *interfaces.AnalysisResult) {
+	if result.BaselineFile == "" {
+		return
+	}
+
+	fmt.Printf("ベースラインで許容: %d件\n", len(result.BaselineDiffs))
+	if len(result.StaleBaselineEntries) > 0 {
+		fmt.Printf("⚠️  ベースラインの %d件 は検出されなくなりました（%s から削除できます）\n", len(result.StaleBaselineEntries), result.BaselineFile)
+	}
+}
+
+// PrintDuplicates は環境内で重複した定義を出力する
+func (s *OutputService) PrintDuplicates(duplicates []*types.DuplicateDefinition) {
+	if len(duplicates) == 0 {
+		return
+	}
+
+	fmt.Printf("❌ 環境内で重複した定義: %d件（比較から除外しました）\n", len(duplicates))
+	for _, duplicate := range duplicates {
+		locations := make([]string, len(duplicate.Locations))
+		for i, location := range duplicate.Locations {
+			locations[i] = location.String()
+		}
+		fmt.Printf("  [%s] %s: %s\n", duplicate.Environment, duplicate.Address, strings.Join(locations, ", "))
+	}
+}
+
+// PrintStackSummary はスタックごとと合計のサマリーを出力し、構成ドリフトの合計件数を返す
+func (s *OutputService) PrintStackSummary(results []*interfaces.StackResult) int {
+	var totalIgnored, totalDrift int
+
+	fmt.Printf("\n=== サマリー ===\n")
+	for _, stackResult := range results {
+		ignoredCount, driftCount := s.classifyDiffs(stackResult.Result.AllDiffs())
+		fmt.Printf("[%s] 意図的な差分: %d件 / 構成ドリフト: %d件\n", stackResult.Name, ignoredCount, driftCount)
+		totalIgnored += ignoredCount
+		totalDrift += driftCount
+	}
+	fmt.Printf("合計 意図的な差分: %d件\n", totalIgnored)
+	fmt.Printf("合計 構成ドリフト: %d件\n", totalDrift)
+
+	return totalDrift
+}
+
+// watchSummaryLimit はwatchモードのサマリーに表示する変化の最大件数
+const watchSummaryLimit = 20
+
+// PrintWatchSummary は前回の実行からの差分の変化を1行ずつ出力する（watchモード用、previousがnilの場合は初回）
+// + は新たに検出された差分、~ は値が変わった差分、- は検出されなくなった差分
+func (s *OutputService) PrintWatchSummary(previous, current []*types.DiffResult) {
+	ignoredCount, driftCount := s.classifyDiffs(current)
+	if previous == nil {
+		fmt.Printf("意図的な差分: %d件 / 構成ドリフト: %d件\n", ignoredCount, driftCount)
+		return
+	}
+
+	previousIgnored, previousDrift := s.classifyDiffs(previous)
+	fmt.Printf("意図的な差分: %d件 (%+d) / 構成ドリフト: %d件 (%+d)\n", ignoredCount, ignoredCount-previousIgnored, driftCount, driftCount-previousDrift)
+
+	appeared, changed, disappeared := differ.CompareRuns(previous, current)
+	if len(appeared)+len(changed)+len(disappeared) == 0 {
+		fmt.Printf("前回からの変化はありません\n")
+		return
+	}
+
+	var lines []string
+	for _, diff := range appeared {
+		lines = append(lines, s.formatWatchLine("+", diff))
+	}
+	for _, diff := range changed {
+		lines = append(lines, s.formatWatchLine("~", diff))
+	}
+	for _, diff := range disappeared {
+		lines = append(lines, s.formatWatchLine("-", diff))
+	}
+
+	fmt.Printf("\n前回からの変化:\n")
+	for i, line := range lines {
+		if i == watchSummaryLimit {
+			fmt.Printf("  ...他%d件\n", len(lines)-watchSummaryLimit)
+			break
+		}
+		fmt.Printf("  %s\n", line)
+	}
+}
+
+// formatWatchLine は差分を「記号 種類 環境 アドレス: 比較元の値 → 環境の値」の1行にする（存在差分は✅/❌で表す）
+func (s *OutputService) formatWatchLine(mark string, diff *types.DiffResult) string {
+	kind := "構成ドリフト"
+	if diff.IsIgnored {
+		kind = "意図的な差分"
+	}
+	address := diff.Resource
+	if diff.Path != "" {
+		address += "." + diff.Path
+	}
+	if isExistenceDiff(diff) {
+		return fmt.Sprintf("%s %s %s %s: %s → %s", mark, kind, diff.Environment, address, existenceMark(diff.Expected), existenceMark(diff.Actual))
+	}
+	return fmt.Sprintf("%s %s %s %s: %s → %s", mark, kind, diff.Environment, address,
+		truncateValue(s.formatter.FormatValue(diff.Expected)), truncateValue(s.formatter.FormatValue(diff.Actual)))
+}
+
+// existenceMark は存在差分の値をレポートと同じ記号にする
+func existenceMark(value cty.Value) string {
+	if value.True() {
+		return "✅"
+	}
+	return "❌"
+}
+
+// truncateValue は1行に収まるよう値を切り詰める
+func truncateValue(value string) string {
+	const maxLength = 40
+	value = strings.ReplaceAll(value, "\n", " ")
+	if runes := []rune(value); len(runes) > maxLength {
+		return string(runes[:maxLength]) + "..."
+	}
+	return value
+}
+
 // classifyDiffs は差分を分類してカウントする
 func (s *OutputService) classifyDiffs(diffs []*types.DiffResult) (int, int) {
 	var ignoredCount, driftCount int
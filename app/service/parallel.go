@@ -0,0 +1,29 @@
+package service
+
+import "sync"
+
+// runParallel は0からn-1までの処理を最大jobs個ずつ並行して実行する
+// 結果は呼び出し側でインデックスごとに保持し、順序を一定にする
+func runParallel(n, jobs int, task func(i int)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(jobs, n); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				task(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Mkamono/tfspec/app/git"
+)
+
+// exportRevision は現在のディレクトリを含むgitリポジトリの指定ref時点のファイルを一時ディレクトリに展開する
+// 返り値の関数で一時ディレクトリを削除する
+func exportRevision(ref string) (*git.Repository, string, func(), error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("現在のディレクトリを取得できませんでした: %w", err)
+	}
+
+	repo, err := git.OpenRepository(cwd)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	revisionDir, err := os.MkdirTemp("", "tfspec-revision-")
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("一時ディレクトリの作成に失敗しました: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(revisionDir) }
+
+	if err := repo.ExportTree(ref, revisionDir); err != nil {
+		cleanup()
+		return nil, "", nil, err
+	}
+
+	return repo, revisionDir, cleanup, nil
+}
@@ -11,6 +11,7 @@ import (
 type AppService struct {
 	configService   interfaces.ConfigServiceInterface
 	analyzerService interfaces.AnalyzerServiceInterface
+	historyService  interfaces.HistoryServiceInterface
 	outputService   interfaces.OutputServiceInterface
 }
 
@@ -18,6 +19,7 @@ func NewAppService() *AppService {
 	return &AppService{
 		configService:   config.NewConfigService(),
 		analyzerService: NewAnalyzerService(),
+		historyService:  NewHistoryService(),
 		outputService:   NewOutputService(),
 	}
 }
@@ -26,19 +28,36 @@ func NewAppService() *AppService {
 func NewAppServiceWithDeps(
 	configService interfaces.ConfigServiceInterface,
 	analyzerService interfaces.AnalyzerServiceInterface,
+	historyService interfaces.HistoryServiceInterface,
 	outputService interfaces.OutputServiceInterface,
 ) *AppService {
 	return &AppService{
 		configService:   configService,
 		analyzerService: analyzerService,
+		historyService:  historyService,
 		outputService:   outputService,
 	}
 }
 
 // RunCheck はcheckコマンドのメインロジックを実行する
-func (s *AppService) RunCheck(envDirs []string, verbose bool, outputFile string, outputFlag bool, noFail bool, excludeDirs []string, maxValueLength int, trimCell bool) error {
+func (s *AppService) RunCheck(options *config.CheckOptions) error {
+	// スタック構成の場合はスタックごとにチェックする
+	stackConfig, err := s.configService.LoadStacks(options)
+	if err != nil {
+		return err
+	}
+	if stackConfig != nil {
+		if options.Watch {
+			return fmt.Errorf("--watch はスタック構成（--stacks）と同時に指定できません")
+		}
+		return s.runStacks(stackConfig)
+	}
+	if options.Watch {
+		return s.runWatch(options)
+	}
+
 	// 設定の読み込み
-	config, err := s.configService.LoadConfig(envDirs, verbose, noFail, excludeDirs)
+	config, err := s.configService.LoadConfig(options)
 	if err != nil {
 		return err
 	}
@@ -50,16 +69,82 @@ func (s *AppService) RunCheck(envDirs []string, verbose bool, outputFile string,
 	}
 
 	// 結果の出力
-	if err := s.outputService.OutputResults(result, outputFile, outputFlag, maxValueLength, trimCell); err != nil {
+	if err := s.outputService.OutputResults(result, config.OutputFile, config.OutputFlag, config.MaxValueLength, config.TrimCell); err != nil {
 		return err
 	}
 
 	// サマリーの表示と結果評価
-	_, driftCount := s.outputService.PrintSummary(result.Diffs)
+	s.outputService.PrintSummary(result.AllDiffs())
+	pendingCount := s.outputService.PrintPendingChanges(result.PendingChanges)
+	s.outputService.PrintBaselineSummary(result)
+	s.outputService.PrintDuplicates(result.Duplicates)
+
+	if config.NoFail {
+		return nil
+	}
+	return evaluateFailOn(config.FailOn, result, pendingCount)
+}
+
+// runStacks は全スタックをチェックし、結果を統合したレポートと終了コードを返す
+func (s *AppService) runStacks(stackConfig *config.StackConfig) error {
+	var results []*interfaces.StackResult
+	var stackConfigs []*config.Config
+
+	for _, stack := range stackConfig.Stacks {
+		fmt.Printf("\n=== スタック: %s ===\n", stack.Name)
+
+		stackCfg, err := s.configService.LoadConfig(stackConfig.Options.ForStack(stack))
+		if err != nil {
+			return fmt.Errorf("スタック %s の設定の読み込みに失敗しました: %w", stack.Name, err)
+		}
 
-	if driftCount > 0 && !config.NoFail {
-		return fmt.Errorf("%d件の構成ドリフトが検出されました", driftCount)
+		result, err := s.analyzerService.Analyze(stackCfg)
+		if err != nil {
+			return fmt.Errorf("スタック %s の分析に失敗しました: %w", stack.Name, err)
+		}
+
+		results = append(results, &interfaces.StackResult{Name: stack.Name, Result: result})
+		stackConfigs = append(stackConfigs, stackCfg)
 	}
 
+	// 結果の出力
+	options := stackConfig.Options
+	if err := s.outputService.OutputStackResults(results, options.OutputFile, options.OutputFlag, options.MaxValueLength, options.TrimCell); err != nil {
+		return err
+	}
+	s.outputService.PrintStackSummary(results)
+
+	// スタックごとに --fail-on を評価し、最も優先度の高い終了コードを返す
+	var failures []*ExitError
+	for i, stackResult := range results {
+		if stackConfigs[i].NoFail {
+			continue
+		}
+		if err := evaluateFailOn(stackConfigs[i].FailOn, stackResult.Result, countPendingChanges(stackResult.Result)); err != nil {
+			exitErr := err.(*ExitError)
+			failures = append(failures, &ExitError{Code: exitErr.Code, Err: fmt.Errorf("スタック %s: %w", stackResult.Name, exitErr.Err)})
+		}
+	}
+	return mostSevereExitError(failures)
+}
+
+// RunConfigShow は設定ファイル・コマンドラインのオプションを反映した有効な設定を表示する
+func (s *AppService) RunConfigShow(options *config.CheckOptions) error {
+	effectiveConfig, err := s.configService.LoadConfig(options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s", config.FormatConfig(effectiveConfig))
 	return nil
-}
\ No newline at end of file
+}
+
+// RunHistory はhistoryコマンドのメインロジックを実行する
+func (s *AppService) RunHistory(options *config.HistoryOptions) error {
+	result, err := s.historyService.Analyze(options)
+	if err != nil {
+		return err
+	}
+
+	return s.outputService.OutputHistory(result, options.OutputFile, options.OutputFlag, options.MaxValueLength, options.TrimCell)
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Mkamono/tfspec/app/config"
+	"github.com/Mkamono/tfspec/app/interfaces"
+	"github.com/Mkamono/tfspec/app/types"
+	"github.com/fsnotify/fsnotify"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// watchDebounce は連続したファイル変更をまとめて1回のチェックにするための待ち時間
+const watchDebounce = 200 * time.Millisecond
+
+// watchExtensions は変更時にチェックを再実行するファイルの拡張子（.tfspec/内のファイルは拡張子によらず対象）
+var watchExtensions = map[string]bool{
+	".tf":     true,
+	".hcl":    true,
+	".tfvars": true,
+}
+
+// runWatch は環境ディレクトリと.tfspec/の変更を監視し、変更のたびにチェックを再実行する
+// 変更のないファイルは解析結果のキャッシュを再利用し、前回の実行から検出・解消された差分をサマリーとして表示する
+func (s *AppService) runWatch(options *config.CheckOptions) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("ファイルの監視を開始できませんでした: %w", err)
+	}
+	defer watcher.Close()
+
+	// 基準ディレクトリ（未指定の場合は現在のディレクトリ）は環境・.tfspec/の追加の検出用に直下のみ監視する
+	// 設定を読み込んだ後は設定の基準ディレクトリを監視する（同じディレクトリを重複して監視しないよう絶対パスにする）
+	baseDir, err := filepath.Abs(options.BaseDir)
+	if err != nil {
+		return fmt.Errorf("ファイルの監視を開始できませんでした: %w", err)
+	}
+	if err := watcher.Add(baseDir); err != nil {
+		return fmt.Errorf("ファイルの監視を開始できませんでした: %w", err)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	var previous []*types.DiffResult
+	var ignorePaths map[string]bool
+	trigger := "初回"
+	for {
+		clearScreen()
+		fmt.Printf("=== %s tfspec check --watch（%s） ===\n", time.Now().Format("15:04:05"), trigger)
+
+		cfg, result, err := s.runWatchCheck(options)
+		if err != nil {
+			// 編集途中の構文エラー等は表示して監視を続ける（前回の結果は保持する）
+			fmt.Printf("❌ %v\n", err)
+		} else {
+			current := result.AllDiffs()
+			s.outputService.PrintWatchSummary(previous, current)
+			s.outputService.PrintDuplicates(result.Duplicates)
+			previous = current
+		}
+		if cfg != nil {
+			ignorePaths = watchIgnorePaths(cfg)
+			if err := watcher.Add(cfg.BaseDir); err != nil {
+				fmt.Printf("⚠️  %s を監視できません（環境・.tfspec/の追加を検出できません）: %v\n", cfg.BaseDir, err)
+			}
+			// 監視できないディレクトリ（inotifyの上限に達した場合等）は変更を検出できないため表示する
+			if err := addWatchDirs(watcher, watchDirs(cfg, result)); err != nil {
+				fmt.Printf("⚠️  一部のディレクトリを監視できません（変更を検出できません）:\n%v\n", err)
+			}
+		}
+		fmt.Printf("\n👀 変更を監視しています（Ctrl+Cで終了）\n")
+
+		changed, ok := waitForChanges(watcher, interrupt, ignorePaths)
+		if !ok {
+			return nil
+		}
+		trigger = "変更: " + changed
+	}
+}
+
+// runWatchCheck は設定を読み込み直してチェックを実行する（設定ファイル・無視ルールの変更も反映する）
+// 解析に失敗した場合も、監視対象を決めるため読み込めた設定は返す
+func (s *AppService) runWatchCheck(options *config.CheckOptions) (*config.Config, *interfaces.AnalysisResult, error) {
+	cfg, err := s.configService.LoadConfig(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := s.analyzerService.Analyze(cfg)
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	if cfg.OutputFlag {
+		if err := s.outputService.WriteResults(result, cfg.OutputFile, cfg.MaxValueLength, cfg.TrimCell); err != nil {
+			return cfg, nil, err
+		}
+	}
+	return cfg, result, nil
+}
+
+// waitForChanges は対象のファイルが変更されるまで待ち、変更されたファイルを返す
+// 連続した変更はwatchDebounceの間まとめる。中断された場合はfalseを返す
+func waitForChanges(watcher *fsnotify.Watcher, interrupt <-chan os.Signal, ignorePaths map[string]bool) (string, bool) {
+	var changed []string
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-interrupt:
+			return "", false
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return "", false
+			}
+			if !isWatchTarget(event, ignorePaths) {
+				continue
+			}
+			changed = appendUnique(changed, event.Name)
+			debounce = time.After(watchDebounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return "", false
+			}
+			fmt.Printf("⚠️  ファイルの監視でエラーが発生しました: %v\n", err)
+
+		case <-debounce:
+			if len(changed) > 3 {
+				return fmt.Sprintf("%s 他%d件", strings.Join(changed[:3], ", "), len(changed)-3), true
+			}
+			return strings.Join(changed, ", "), true
+		}
+	}
+}
+
+// isWatchTarget はチェックを再実行する変更かどうかを判定する
+// レポート・キャッシュ等のtfspec自身が書き込むファイルは対象外とする
+func isWatchTarget(event fsnotify.Event, ignorePaths map[string]bool) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	path, err := filepath.Abs(event.Name)
+	if err != nil {
+		return false
+	}
+	for ignorePath := range ignorePaths {
+		if path == ignorePath || strings.HasPrefix(path, ignorePath+string(filepath.Separator)) {
+			return false
+		}
+	}
+
+	if watchExtensions[filepath.Ext(path)] {
+		return true
+	}
+	// .tfspec/内のファイル（.tfspecignore等）と、新しく作成されたディレクトリ（環境の追加）
+	if strings.Contains(path, string(filepath.Separator)+".tfspec"+string(filepath.Separator)) {
+		return true
+	}
+	if event.Op&fsnotify.Create != 0 {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// watchIgnorePaths はtfspec自身が書き込むため監視の対象外とするパスを返す
+func watchIgnorePaths(cfg *config.Config) map[string]bool {
+	ignorePaths := make(map[string]bool)
+	if cfg.TfspecDir != "" {
+		if path, err := filepath.Abs(filepath.Join(cfg.TfspecDir, "cache")); err == nil {
+			ignorePaths[path] = true
+		}
+	}
+	if cfg.OutputFlag {
+		if path, err := filepath.Abs(cfg.OutputFile); err == nil {
+			ignorePaths[path] = true
+		}
+	}
+	return ignorePaths
+}
+
+// watchDirs はサブディレクトリを含めて監視するディレクトリを返す
+// 環境ディレクトリ、.tfspec/、環境が参照するローカルモジュール（解析できた場合のみ）が対象
+func watchDirs(cfg *config.Config, result *interfaces.AnalysisResult) []string {
+	var dirs []string
+	if cfg.TfspecDir != "" {
+		dirs = append(dirs, cfg.TfspecDir)
+	}
+	for _, envDir := range cfg.EnvDirs {
+		dirs = append(dirs, envDir)
+		if result == nil {
+			continue
+		}
+		if envResources, exists := result.EnvResources[cfg.EnvNames[envDir]]; exists {
+			dirs = append(dirs, localModuleDirs(envDir, envResources)...)
+		}
+	}
+	return dirs
+}
+
+// localModuleDirs は解析できたローカルモジュールのディレクトリを再帰的に返す
+func localModuleDirs(baseDir string, envResources *types.EnvResources) []string {
+	var dirs []string
+	for _, module := range envResources.Modules {
+		source, exists := module.Attrs["source"]
+		if module.Children == nil || !exists || source.IsNull() || !source.IsKnown() || source.Type() != cty.String {
+			continue
+		}
+		moduleDir := filepath.Join(baseDir, source.AsString())
+		dirs = append(dirs, moduleDir)
+		dirs = append(dirs, localModuleDirs(moduleDir, module.Children)...)
+	}
+	return dirs
+}
+
+// addWatchDirs はディレクトリとそのサブディレクトリを監視に追加する（追加済みのディレクトリは無視される）
+// 読み込めない・監視に追加できないディレクトリがあっても残りのディレクトリは追加し、全てのエラーをまとめて返す
+func addWatchDirs(watcher *fsnotify.Watcher, dirs []string) error {
+	var errs []error
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+			if !entry.IsDir() {
+				return nil
+			}
+			if path != dir && isSkippedWatchDir(path) {
+				return filepath.SkipDir
+			}
+			if err := watcher.Add(path); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			}
+			return nil
+		})
+	}
+	return errors.Join(errs...)
+}
+
+// isSkippedWatchDir は監視しないサブディレクトリ（隠しディレクトリ、.tfspec/cache/）かどうかを判定する
+func isSkippedWatchDir(path string) bool {
+	name := filepath.Base(path)
+	if name == "cache" && filepath.Base(filepath.Dir(path)) == ".tfspec" {
+		return true
+	}
+	return strings.HasPrefix(name, ".") && name != ".tfspec"
+}
+
+// appendUnique は重複しない場合のみ値を追加する
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// clearScreen は標準出力が端末の場合に画面を消去する
+func clearScreen() {
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Print("\033[H\033[2J")
+	}
+}
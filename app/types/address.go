@@ -0,0 +1,257 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// アドレスが指すブロックの種類
+const (
+	KindResource   = "resource"
+	KindData       = "data"
+	KindModule     = "module"
+	KindVariable   = "variable"
+	KindLocal      = "local"
+	KindOutput     = "output"
+	KindProvider   = "provider"
+	KindTerraform  = "terraform"
+	KindTerragrunt = "terragrunt"
+	KindMoved      = "moved"
+	KindImport     = "import"
+	KindRemoved    = "removed"
+	KindCheck      = "check"
+)
+
+// Address はブロック（とその属性）を指す正規のアドレス
+// 差分の検出・無視ルールの判定と検証・レポートの表示で同じ形式を使う
+// （例: module.app.data.aws_ami.ubuntu[0].tags.Name -> Module: [app], Kind: data, Type: aws_ami, Name: ubuntu, Key: 0, Path: tags.Name）
+type Address struct {
+	Module []string // ローカルモジュールの内部構成の場合はモジュール名（外側から順に）
+	Kind   string   // Kind* のいずれか
+	Type   string   // resource / data のリソースタイプ（aws_instance等）。他の種類は空
+	Name   string   // ブロック名（provider: プロバイダ名、moved / removed: from、import: to）
+	Key    string   // count / for_each のインスタンスキー（0, "a"等）、providerのalias。ない場合は空
+	Path   string   // 属性パス（tags.Name, ingress[0].from_port等）。ブロック自体を指す場合は空
+}
+
+// ResourceAddress はresourceのアドレスを返す
+func ResourceAddress(resourceType, name, key string) Address {
+	return Address{Kind: KindResource, Type: resourceType, Name: name, Key: key}
+}
+
+// DataAddress はdataソースのアドレスを返す
+func DataAddress(dataType, name, key string) Address {
+	return Address{Kind: KindData, Type: dataType, Name: name, Key: key}
+}
+
+// ProviderAddress はproviderのアドレスを返す（aliasはインスタンスキーとして扱う）
+func ProviderAddress(name, alias string) Address {
+	return Address{Kind: KindProvider, Name: name, Key: alias}
+}
+
+// BlockAddress は名前で識別するブロック（module, variable, local, output, moved, import, removed, check）と
+// 名前を持たないブロック（terraform, terragrunt）のアドレスを返す
+func BlockAddress(kind, name string) Address {
+	return Address{Kind: kind, Name: name}
+}
+
+// Resource は属性パスを含まないブロックのアドレスを返す（差分のResourceに使用する形式）
+func (a Address) Resource() string {
+	var b strings.Builder
+	for _, module := range a.Module {
+		b.WriteString("module." + module + ".")
+	}
+
+	switch a.Kind {
+	case KindResource:
+		b.WriteString(a.instanceName())
+	case KindData:
+		b.WriteString("data." + a.instanceName())
+	case KindVariable:
+		b.WriteString("var." + a.Name)
+	case KindProvider:
+		b.WriteString("provider." + a.Name)
+		if a.Key != "" {
+			b.WriteString("[" + a.Key + "]")
+		}
+	case KindTerraform, KindTerragrunt:
+		b.WriteString(a.Kind)
+	case "":
+		b.WriteString(a.Name) // 解析できなかったアドレス
+	default:
+		b.WriteString(a.Kind + "." + a.Name)
+	}
+	return b.String()
+}
+
+// String は属性パスを含むアドレスを返す（.tfspecignoreのルールと同じ形式）
+func (a Address) String() string {
+	if a.Path == "" {
+		return a.Resource()
+	}
+	return a.Resource() + "." + a.Path
+}
+
+// DisplayName はレポートのリソース名として表示する名前を返す（種類はリソースタイプとして別に表示する）
+// （例: aws_instance.web[0], aws_ami.ubuntu, app, module.app.aws_instance.web）
+func (a Address) DisplayName() string {
+	var b strings.Builder
+	for _, module := range a.Module {
+		b.WriteString("module." + module + ".")
+	}
+
+	switch a.Kind {
+	case KindResource, KindData:
+		b.WriteString(a.instanceName())
+	case KindProvider:
+		b.WriteString(a.Name)
+		if a.Key != "" {
+			b.WriteString("[" + a.Key + "]")
+		}
+	case KindTerraform, KindTerragrunt:
+	default:
+		b.WriteString(a.Name)
+	}
+	return strings.TrimSuffix(b.String(), ".")
+}
+
+// instanceName はresource / dataの type.name[key] 形式の名前を返す
+func (a Address) instanceName() string {
+	if a.Key == "" {
+		return a.Type + "." + a.Name
+	}
+	return a.Type + "." + a.Name + "[" + a.Key + "]"
+}
+
+// WithPath は属性パスを付けたアドレスを返す（既にパスがある場合は子パスにする）
+func (a Address) WithPath(path string) Address {
+	switch {
+	case path == "":
+	case a.Path == "":
+		a.Path = path
+	default:
+		a.Path = a.Path + "." + path
+	}
+	return a
+}
+
+// WithoutKey はインスタンスキー（providerのalias）を取り除いたアドレスを返す
+func (a Address) WithoutKey() Address {
+	a.Key = ""
+	return a
+}
+
+// InModule はモジュールの内部構成のアドレスとして、外側にモジュールを加えたアドレスを返す
+func (a Address) InModule(module string) Address {
+	a.Module = append([]string{module}, a.Module...)
+	return a
+}
+
+// ParseAddress は属性パスを含まないブロックのアドレス（差分のResource）を解析する
+// インスタンスキー内の "." は区切りとして扱わない（aws_subnet.az["ap-northeast-1.a"] 等）
+func ParseAddress(address string) (Address, error) {
+	segments := splitAddress(address)
+	parsed, ok := parseBlockSegments(segments)
+	if !ok {
+		return Address{}, fmt.Errorf("アドレス '%s' を解析できません", address)
+	}
+	return parsed, nil
+}
+
+// ParseAttributeAddress は属性パスを含むアドレス（.tfspecignoreのルール等）として解釈できる候補を返す
+// module.app.instance_type（モジュールの属性）と module.app.aws_instance.web（モジュール内部のリソース）、
+// movedのfromのような任意の長さのアドレスを区別できないため、ブロックのアドレスが長い順に全ての解釈を返す
+func ParseAttributeAddress(address string) []Address {
+	segments := splitAddress(address)
+
+	var candidates []Address
+	for i := len(segments); i > 0; i-- {
+		parsed, ok := parseBlockSegments(segments[:i])
+		if !ok {
+			continue
+		}
+		parsed.Path = strings.Join(segments[i:], ".")
+		candidates = append(candidates, parsed)
+	}
+	return candidates
+}
+
+// parseBlockSegments は区切った要素全体をブロックのアドレスとして解析する
+func parseBlockSegments(segments []string) (Address, bool) {
+	var address Address
+	for len(segments) > 2 && segments[0] == "module" {
+		address.Module = append(address.Module, segments[1])
+		segments = segments[2:]
+	}
+	if len(segments) == 0 {
+		return Address{}, false
+	}
+
+	switch segments[0] {
+	case "terraform", "terragrunt":
+		address.Kind = segments[0]
+		return address, len(segments) == 1
+	case "moved", "import", "removed":
+		// from / to には任意のアドレスを指定できる
+		address.Kind = segments[0]
+		address.Name = strings.Join(segments[1:], ".")
+		return address, len(segments) >= 2
+	case "data":
+		if len(segments) != 3 {
+			return Address{}, false
+		}
+		address.Kind = KindData
+		address.Type = segments[1]
+		address.Name, address.Key = splitInstanceKey(segments[2])
+		return address, true
+	}
+
+	if len(segments) != 2 {
+		return Address{}, false
+	}
+	switch segments[0] {
+	case "module", "local", "output", "check":
+		address.Kind = segments[0]
+		address.Name = segments[1]
+	case "var":
+		address.Kind = KindVariable
+		address.Name = segments[1]
+	case "provider":
+		address.Kind = KindProvider
+		address.Name, address.Key = splitInstanceKey(segments[1])
+	default:
+		address.Kind = KindResource
+		address.Type = segments[0]
+		address.Name, address.Key = splitInstanceKey(segments[1])
+	}
+	return address, true
+}
+
+// splitAddress はアドレスを "." で区切る（[] と "" の中の "." は区切らない）
+func splitAddress(address string) []string {
+	var segments []string
+	depth, quoted, start := 0, false, 0
+	for i := 0; i < len(address); i++ {
+		switch c := address[i]; {
+		case c == '"' && (i == 0 || address[i-1] != '\\'):
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '.' && depth == 0:
+			segments = append(segments, address[start:i])
+			start = i + 1
+		}
+	}
+	return append(segments, address[start:])
+}
+
+// splitInstanceKey は name[key] を名前とインスタンスキーに分ける
+func splitInstanceKey(segment string) (string, string) {
+	if index := strings.Index(segment, "["); index != -1 && strings.HasSuffix(segment, "]") {
+		return segment[:index], segment[index+1 : len(segment)-1]
+	}
+	return segment, ""
+}
@@ -0,0 +1,173 @@
+package types
+
+import (
+	"sort"
+)
+
+// DuplicateDefinition は同じ環境内で重複して定義されたresource・data・module・variable・output・local
+type DuplicateDefinition struct {
+	Environment string
+	Kind        string           // resource, data, module, variable, output, local
+	Address     string           // aws_instance.web, data.aws_ami.ubuntu, module.app.var.name 等（インスタンスキーなし）
+	Locations   []SourceLocation // 定義した位置（定義順）
+}
+
+// definition は重複の検出に使う定義のアドレス（インスタンスキーなし）と位置
+type definition struct {
+	address  Address
+	location SourceLocation
+}
+
+// Duplicates は重複して定義されたアドレスを返す（Environmentは呼び出し側で設定する）
+// count / for_each で展開したインスタンスは同じブロックの定義のため、異なる位置で定義された場合のみ重複とする
+// ローカルモジュールの内部構成はモジュールを含むアドレス（module.app.aws_instance.web）で検出する
+func (r *EnvResources) Duplicates() []*DuplicateDefinition {
+	duplicates := r.duplicates(nil)
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Address < duplicates[j].Address
+	})
+	return duplicates
+}
+
+func (r *EnvResources) duplicates(modules []string) []*DuplicateDefinition {
+	var duplicates []*DuplicateDefinition
+	byAddress := make(map[string]*DuplicateDefinition)
+	var addresses []string
+
+	for _, def := range r.definitions() {
+		if def.location.IsZero() {
+			continue
+		}
+		def.address.Module = modules
+		address := def.address.String()
+		duplicate, exists := byAddress[address]
+		if !exists {
+			duplicate = &DuplicateDefinition{Kind: def.address.Kind, Address: address}
+			byAddress[address] = duplicate
+			addresses = append(addresses, address)
+		}
+		if !containsLocation(duplicate.Locations, def.location) {
+			duplicate.Locations = append(duplicate.Locations, def.location)
+		}
+	}
+
+	for _, address := range addresses {
+		if duplicate := byAddress[address]; len(duplicate.Locations) > 1 {
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+
+	for _, module := range r.Modules {
+		if module.Children != nil {
+			duplicates = append(duplicates, module.Children.duplicates(childModules(modules, module.Name))...)
+		}
+	}
+	return duplicates
+}
+
+// RemoveDefinitions は指定したアドレス（Duplicatesと同じ形式）の定義を全て取り除く
+// 重複した定義はどれを比較すべきか決められないため、差分の検出前に取り除くために使う
+func (r *EnvResources) RemoveDefinitions(addresses map[string]bool) {
+	r.removeDefinitions(addresses, nil)
+}
+
+func (r *EnvResources) removeDefinitions(addresses map[string]bool, modules []string) {
+	removed := func(address Address) bool {
+		address.Module = modules
+		return addresses[address.WithoutKey().String()]
+	}
+
+	resources := r.Resources[:0]
+	for _, resource := range r.Resources {
+		if !removed(resource.Address()) {
+			resources = append(resources, resource)
+		}
+	}
+	r.Resources = resources
+
+	dataSources := r.DataSources[:0]
+	for _, data := range r.DataSources {
+		if !removed(data.Address()) {
+			dataSources = append(dataSources, data)
+		}
+	}
+	r.DataSources = dataSources
+
+	moduleList := r.Modules[:0]
+	for _, module := range r.Modules {
+		if removed(module.Address()) {
+			continue
+		}
+		if module.Children != nil {
+			module.Children.removeDefinitions(addresses, childModules(modules, module.Name))
+		}
+		moduleList = append(moduleList, module)
+	}
+	r.Modules = moduleList
+
+	variables := r.Variables[:0]
+	for _, variable := range r.Variables {
+		if !removed(variable.Address()) {
+			variables = append(variables, variable)
+		}
+	}
+	r.Variables = variables
+
+	outputs := r.Outputs[:0]
+	for _, output := range r.Outputs {
+		if !removed(output.Address()) {
+			outputs = append(outputs, output)
+		}
+	}
+	r.Outputs = outputs
+
+	locals := r.Locals[:0]
+	for _, local := range r.Locals {
+		if !removed(local.Address()) {
+			locals = append(locals, local)
+		}
+	}
+	r.Locals = locals
+
+	// 取り除いた定義が索引に残らないよう作り直させる
+	r.index = nil
+}
+
+// definitions は重複の検出対象となる定義を定義順に返す
+func (r *EnvResources) definitions() []definition {
+	var defs []definition
+	for _, resource := range r.Resources {
+		defs = append(defs, definition{resource.Address().WithoutKey(), resource.Location})
+	}
+	for _, data := range r.DataSources {
+		defs = append(defs, definition{data.Address().WithoutKey(), data.Location})
+	}
+	for _, module := range r.Modules {
+		defs = append(defs, definition{module.Address(), module.Location})
+	}
+	for _, variable := range r.Variables {
+		defs = append(defs, definition{variable.Address(), variable.Location})
+	}
+	for _, output := range r.Outputs {
+		defs = append(defs, definition{output.Address(), output.Location})
+	}
+	for _, local := range r.Locals {
+		defs = append(defs, definition{local.Address(), local.Location})
+	}
+	return defs
+}
+
+// childModules はモジュールの内部構成のアドレスに付けるモジュール名（外側から順に）を返す
+func childModules(modules []string, name string) []string {
+	return append(append([]string{}, modules...), name)
+}
+
+// containsLocation は位置が含まれているかどうかを判定する
+func containsLocation(locations []SourceLocation, location SourceLocation) bool {
+	for _, existing := range locations {
+		if existing == location {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package types
+
+// ResourceIndex はリソース・dataソース・モジュールをアドレスで引く索引
+// 大規模な環境でもdifferでの対応付けとreporterでの値の補填を定数時間で行うため、環境ごとに1度だけ構築して共有する
+// キーはモジュールを含まないアドレスの文字列（Address.String()）
+type ResourceIndex struct {
+	resources         map[string][]*EnvResource // aws_instance.web[0] -> リソース（同じアドレスが重複する場合は定義順に全て）
+	resourceAddresses []Address                 // リソースのアドレス（定義順、重複なし）
+	dataSources       map[string]*EnvData       // data.aws_ami.ubuntu -> dataソース
+	modules           map[string]*EnvModule     // module.app -> モジュール
+}
+
+// Index は環境の索引を返す（初回の呼び出し時に構築する）
+// 解析後の構成は変更しない前提のため、構築した索引はそのまま再利用する
+func (r *EnvResources) Index() *ResourceIndex {
+	if r.index != nil {
+		return r.index
+	}
+
+	index := &ResourceIndex{
+		resources:   make(map[string][]*EnvResource, len(r.Resources)),
+		dataSources: make(map[string]*EnvData, len(r.DataSources)),
+		modules:     make(map[string]*EnvModule, len(r.Modules)),
+	}
+	for _, resource := range r.Resources {
+		address := resource.Address()
+		key := address.String()
+		if _, exists := index.resources[key]; !exists {
+			index.resourceAddresses = append(index.resourceAddresses, address)
+		}
+		index.resources[key] = append(index.resources[key], resource)
+	}
+	// dataソース・モジュールが重複する場合は先に定義されたものを使う
+	for _, data := range r.DataSources {
+		key := data.Address().String()
+		if _, exists := index.dataSources[key]; !exists {
+			index.dataSources[key] = data
+		}
+	}
+	for _, module := range r.Modules {
+		key := module.Address().String()
+		if _, exists := index.modules[key]; !exists {
+			index.modules[key] = module
+		}
+	}
+
+	r.index = index
+	return index
+}
+
+// Resource はアドレス（インスタンスキーを含む）でリソースを返す（存在しない場合はnil、重複する場合は先に定義されたもの）
+func (i *ResourceIndex) Resource(address Address) *EnvResource {
+	if resources := i.resources[address.String()]; len(resources) > 0 {
+		return resources[0]
+	}
+	return nil
+}
+
+// Resources はアドレスが一致する全てのリソースを定義順に返す
+func (i *ResourceIndex) Resources(address Address) []*EnvResource {
+	return i.resources[address.String()]
+}
+
+// Data はアドレス（インスタンスキーを含む）でdataソースを返す（存在しない場合はnil）
+func (i *ResourceIndex) Data(address Address) *EnvData {
+	return i.dataSources[address.String()]
+}
+
+// Module は名前でモジュールを返す（存在しない場合はnil）
+func (i *ResourceIndex) Module(name string) *EnvModule {
+	return i.modules[BlockAddress(KindModule, name).String()]
+}
+
+// HasResource はアドレスのリソースが存在するかどうかを返す
+func (i *ResourceIndex) HasResource(address Address) bool {
+	_, exists := i.resources[address.String()]
+	return exists
+}
+
+// ResourceAddresses は全リソースのアドレスを定義順に返す
+func (i *ResourceIndex) ResourceAddresses() []Address {
+	return i.resourceAddresses
+}
@@ -0,0 +1,284 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Lookup はアドレスが指すブロックを属性・ネストブロックを持つEnvResourceとして返す（存在しない場合はnil）
+// モジュールの内部構成はアドレスのModuleをたどって検索する
+func (r *EnvResources) Lookup(address Address) *EnvResource {
+	if scope := r.Scope(address.Module); scope != nil {
+		return scope.lookupBlock(address)
+	}
+	return nil
+}
+
+// LookupValue はアドレス（属性パスを含む）が指す値を返す
+// 属性パスのないアドレスはブロックが存在する場合にtrueを返す（値はcty.NilVal）
+// インスタンスキーのないresource / data / providerのアドレスは、いずれかのインスタンスに存在すればよい
+func (r *EnvResources) LookupValue(address Address) (cty.Value, bool) {
+	scope := r.Scope(address.Module)
+	if scope == nil {
+		return cty.NilVal, false
+	}
+
+	var blocks []*EnvResource
+	if address.Key == "" && (address.Kind == KindResource || address.Kind == KindData || address.Kind == KindProvider) {
+		blocks = scope.instances(address)
+	} else if block := scope.lookupBlock(address); block != nil {
+		blocks = []*EnvResource{block}
+	}
+
+	for _, block := range blocks {
+		if address.Path == "" {
+			return cty.NilVal, true
+		}
+		if value, exists := block.Lookup(address.Path); exists {
+			return value, true
+		}
+	}
+	return cty.NilVal, false
+}
+
+// instances はインスタンスキーを除いたアドレスが一致する全てのインスタンスを返す
+func (r *EnvResources) instances(address Address) []*EnvResource {
+	var blocks []*EnvResource
+	switch address.Kind {
+	case KindResource:
+		for _, resource := range r.Resources {
+			if resource.Type == address.Type && resource.Name == address.Name {
+				blocks = append(blocks, resource)
+			}
+		}
+	case KindData:
+		for _, data := range r.DataSources {
+			if data.Type == address.Type && data.Name == address.Name {
+				blocks = append(blocks, &EnvResource{Type: data.Type, Name: data.Name, Key: data.Key, Attrs: data.Attrs, Blocks: data.Blocks})
+			}
+		}
+	case KindProvider:
+		for _, provider := range r.Providers {
+			if provider.Name == address.Name {
+				blocks = append(blocks, &EnvResource{Type: KindProvider, Name: provider.Name, Key: provider.Alias, Attrs: provider.Attrs, Blocks: provider.Blocks})
+			}
+		}
+	}
+	return blocks
+}
+
+// Scope はモジュール名（外側から順に）をたどったモジュールの内部構成を返す
+// モジュールが存在しない、または内部構成を持たない場合はnilを返す
+func (r *EnvResources) Scope(modules []string) *EnvResources {
+	scope := r
+	for _, moduleName := range modules {
+		if scope == nil {
+			return nil
+		}
+		module := scope.Index().Module(moduleName)
+		if module == nil || module.Children == nil {
+			return nil
+		}
+		scope = module.Children
+	}
+	return scope
+}
+
+// lookupBlock は現在の構成（モジュールの内部構成を含まない）からブロックを検索する
+func (r *EnvResources) lookupBlock(address Address) *EnvResource {
+	address.Module, address.Path = nil, ""
+	switch address.Kind {
+	case KindResource:
+		if resource := r.Index().Resource(address); resource != nil {
+			return resource
+		}
+
+	case KindData:
+		if data := r.Index().Data(address); data != nil {
+			return &EnvResource{Type: data.Type, Name: data.Name, Key: data.Key, Attrs: data.Attrs, Blocks: data.Blocks}
+		}
+
+	case KindModule:
+		if module := r.Index().Module(address.Name); module != nil {
+			return &EnvResource{Type: KindModule, Name: module.Name, Attrs: module.Attrs}
+		}
+
+	case KindVariable:
+		for _, variable := range r.Variables {
+			if variable.Name == address.Name {
+				return &EnvResource{Type: KindVariable, Name: variable.Name, Attrs: variable.Attrs}
+			}
+		}
+
+	case KindLocal:
+		for _, local := range r.Locals {
+			if local.Name == address.Name {
+				return &EnvResource{Type: KindLocal, Name: local.Name}
+			}
+		}
+
+	case KindOutput:
+		for _, output := range r.Outputs {
+			if output.Name == address.Name {
+				return &EnvResource{Type: KindOutput, Name: output.Name, Attrs: output.Attrs}
+			}
+		}
+
+	case KindProvider:
+		for _, provider := range r.Providers {
+			if provider.Name == address.Name && provider.Alias == address.Key {
+				return &EnvResource{Type: KindProvider, Name: provider.Name, Key: provider.Alias, Attrs: provider.Attrs, Blocks: provider.Blocks}
+			}
+		}
+
+	case KindTerraform:
+		if r.Terraform != nil {
+			return terraformAsResource(r.Terraform)
+		}
+
+	case KindTerragrunt:
+		if r.Terragrunt != nil {
+			return terragruntAsResource(r.Terragrunt)
+		}
+
+	case KindMoved:
+		for _, moved := range r.Moved {
+			if moved.From == address.Name {
+				return &EnvResource{Type: KindMoved, Name: moved.From, Attrs: moved.Attrs}
+			}
+		}
+
+	case KindImport:
+		for _, imp := range r.Imports {
+			if imp.To == address.Name {
+				return &EnvResource{Type: KindImport, Name: imp.To, Attrs: imp.Attrs}
+			}
+		}
+
+	case KindRemoved:
+		for _, removed := range r.Removed {
+			if removed.From == address.Name {
+				return &EnvResource{Type: KindRemoved, Name: removed.From, Attrs: removed.Attrs, Blocks: removed.Blocks}
+			}
+		}
+
+	case KindCheck:
+		for _, check := range r.Checks {
+			if check.Name == address.Name {
+				return &EnvResource{Type: KindCheck, Name: check.Name, Attrs: check.Attrs, Blocks: check.Blocks}
+			}
+		}
+	}
+	return nil
+}
+
+// terraformAsResource はterraformブロックを差分の属性パス（required_providers.aws.version, backend.s3.bucket等）で
+// たどれる属性を持つEnvResourceに変換する
+func terraformAsResource(terraform *EnvTerraform) *EnvResource {
+	attrs := make(map[string]cty.Value, len(terraform.Attrs)+2)
+	for name, value := range terraform.Attrs {
+		attrs[name] = value
+	}
+
+	requiredProviders := make(map[string]cty.Value, len(terraform.RequiredProviders))
+	for name, providerAttrs := range terraform.RequiredProviders {
+		requiredProviders[name] = cty.ObjectVal(providerAttrs)
+	}
+	attrs["required_providers"] = cty.ObjectVal(requiredProviders)
+
+	if terraform.Backend != nil {
+		attrs["backend"] = cty.ObjectVal(map[string]cty.Value{terraform.Backend.Type: cty.ObjectVal(terraform.Backend.Attrs)})
+	}
+	return &EnvResource{Type: KindTerraform, Attrs: attrs}
+}
+
+// terragruntAsResource はterragrunt.hclの構成を差分の属性パス（inputs.<キー>, terraform.source, remote_state.<config>等）で
+// たどれる属性を持つEnvResourceに変換する
+func terragruntAsResource(terragrunt *EnvTerragrunt) *EnvResource {
+	attrs := make(map[string]cty.Value, len(terragrunt.Attrs)+3)
+	for name, value := range terragrunt.Attrs {
+		attrs[name] = value
+	}
+	attrs["inputs"] = cty.ObjectVal(terragrunt.Inputs)
+	attrs["terraform"] = cty.ObjectVal(terragrunt.TerraformAttrs)
+
+	if terragrunt.RemoteState != nil {
+		remoteState := map[string]cty.Value{"backend": cty.StringVal(terragrunt.RemoteState.Type)}
+		for name, value := range terragrunt.RemoteState.Attrs {
+			remoteState[name] = value
+		}
+		attrs["remote_state"] = cty.ObjectVal(remoteState)
+	}
+	return &EnvResource{Type: KindTerragrunt, Attrs: attrs}
+}
+
+// Lookup は属性パス（tags.Name, ingress[0].from_port, dynamic.ingress[0].from_port等）の値を返す
+// ネストブロック自体を指すパス（ingress[0]）の場合はブロックの属性をオブジェクトとして返す
+func (r *EnvResource) Lookup(path string) (cty.Value, bool) {
+	if path == "" {
+		return cty.NilVal, false
+	}
+	segments := splitAddress(path)
+
+	if value, exists := r.Attrs[segments[0]]; exists {
+		return lookupValue(value, segments[1:])
+	}
+
+	return lookupBlocks(r.Blocks, segments)
+}
+
+// lookupBlocks はネストブロックのパス（ingress[0].from_port, setting[0].rule[1].action等）の値を返す
+// ネストブロック内のネストブロックは再帰的にたどる
+func lookupBlocks(blockGroups map[string][]*EnvBlock, segments []string) (cty.Value, bool) {
+	// ネストブロック（dynamicブロックのテンプレートは "dynamic.<ブロック型>"）
+	blockSegment, rest := segments[0], segments[1:]
+	blockPrefix := ""
+	if blockSegment == "dynamic" && len(rest) > 0 {
+		blockPrefix = "dynamic."
+		blockSegment, rest = rest[0], rest[1:]
+	}
+	blockType, index := splitInstanceKey(blockSegment)
+	position, err := strconv.Atoi(index)
+	blocks := blockGroups[blockPrefix+blockType]
+	if err != nil || position < 0 || position >= len(blocks) {
+		return cty.NilVal, false
+	}
+
+	block := blocks[position]
+	if len(rest) == 0 {
+		return cty.ObjectVal(block.Attrs), true
+	}
+	if value, exists := block.Attrs[rest[0]]; exists {
+		return lookupValue(value, rest[1:])
+	}
+	return lookupBlocks(block.Blocks, rest)
+}
+
+// lookupValue はオブジェクト・マップの値をキーでたどる
+func lookupValue(value cty.Value, segments []string) (cty.Value, bool) {
+	for _, segment := range segments {
+		if value.IsNull() || !value.IsKnown() {
+			return cty.NilVal, false
+		}
+		key := strings.Trim(segment, `"`)
+
+		switch ty := value.Type(); {
+		case ty.IsObjectType():
+			if !ty.HasAttribute(key) {
+				return cty.NilVal, false
+			}
+			value = value.GetAttr(key)
+		case ty.IsMapType():
+			keyValue := cty.StringVal(key)
+			if !value.HasIndex(keyValue).True() {
+				return cty.NilVal, false
+			}
+			value = value.Index(keyValue)
+		default:
+			return cty.NilVal, false
+		}
+	}
+	return value, true
+}
@@ -1,46 +1,178 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/zclconf/go-cty/cty"
 )
 
 // シンプルな構造体定義（新しい.tfspecignore設計用）
 
 type EnvResource struct {
-	Type   string
-	Name   string
-	Attrs  map[string]cty.Value
-	Blocks map[string][]*EnvBlock
+	Type     string
+	Name     string
+	Key      string // count / for_each 展開時のインスタンスキー（例: 0, "ap-northeast-1a"）。展開しない場合は空
+	Attrs    map[string]cty.Value
+	Blocks   map[string][]*EnvBlock
+	Location SourceLocation // 定義したブロックの位置（state / plan から読み込んだ場合は空）
+}
+
+// Address はインスタンスキーを含むリソースのアドレスを返す（例: aws_instance.web[0]）
+func (r *EnvResource) Address() Address {
+	return ResourceAddress(r.Type, r.Name, r.Key)
 }
 
 // 新しいブロックタイプ用の構造体
 type EnvModule struct {
-	Name   string
-	Attrs  map[string]cty.Value
+	Name     string
+	Attrs    map[string]cty.Value
+	Children *EnvResources // ローカルモジュールを入力値で具体化した内部構成（ローカル以外・解析できない場合はnil）
+	Location SourceLocation
 }
 
 type EnvLocal struct {
-	Name  string
-	Value cty.Value
+	Name     string
+	Value    cty.Value
+	Location SourceLocation
 }
 
 type EnvVariable struct {
-	Name   string
-	Attrs  map[string]cty.Value
+	Name     string
+	Attrs    map[string]cty.Value
+	Location SourceLocation
 }
 
 type EnvOutput struct {
-	Name   string
-	Attrs  map[string]cty.Value
+	Name     string
+	Attrs    map[string]cty.Value
+	Location SourceLocation
 }
 
 type EnvData struct {
-	Type   string
-	Name   string
+	Type     string
+	Name     string
+	Key      string // count / for_each 展開時のインスタンスキー。展開しない場合は空
+	Attrs    map[string]cty.Value
+	Blocks   map[string][]*EnvBlock
+	Location SourceLocation
+}
+
+// Address はインスタンスキーを含むデータソースのアドレスを返す（例: data.aws_ami.ubuntu）
+func (d *EnvData) Address() Address {
+	return DataAddress(d.Type, d.Name, d.Key)
+}
+
+// Address はモジュールのアドレスを返す（例: module.app）
+func (m *EnvModule) Address() Address {
+	return BlockAddress(KindModule, m.Name)
+}
+
+// IsLocalModuleSource はモジュールのsourceがローカルパスかどうかを判定する
+func IsLocalModuleSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// Address はlocal値のアドレスを返す（例: local.name）
+func (l *EnvLocal) Address() Address {
+	return BlockAddress(KindLocal, l.Name)
+}
+
+// Address は変数のアドレスを返す（例: var.region）
+func (v *EnvVariable) Address() Address {
+	return BlockAddress(KindVariable, v.Name)
+}
+
+// Address は出力のアドレスを返す（例: output.vpc_id）
+func (o *EnvOutput) Address() Address {
+	return BlockAddress(KindOutput, o.Name)
+}
+
+// SourceLocation はブロック・属性を定義したファイルと行
+type SourceLocation struct {
+	Filename string // 絶対パス
+	Line     int
+}
+
+// IsZero は位置が記録されていない（state / plan から読み込んだ等）かどうかを返す
+func (l SourceLocation) IsZero() bool {
+	return l.Filename == ""
+}
+
+// String は file:line 形式の文字列を返す（ファイルは実行時のディレクトリからの相対パスで表示する）
+func (l SourceLocation) String() string {
+	filename := l.Filename
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, l.Filename); err == nil {
+			filename = filepath.ToSlash(rel)
+		}
+	}
+	return fmt.Sprintf("%s:%d", filename, l.Line)
+}
+
+// EnvProvider はproviderブロック
+type EnvProvider struct {
+	Name   string // プロバイダ名（aws等）
+	Alias  string // alias属性の値。未指定の場合は空
 	Attrs  map[string]cty.Value
 	Blocks map[string][]*EnvBlock
 }
 
+// Address はエイリアスを含むプロバイダのアドレスを返す（例: provider.aws, provider.aws[tokyo]）
+func (p *EnvProvider) Address() Address {
+	return ProviderAddress(p.Name, p.Alias)
+}
+
+// EnvTerraform はterraformブロック（複数ファイルに分かれている場合は結合したもの）
+type EnvTerraform struct {
+	Attrs             map[string]cty.Value            // required_version等
+	RequiredProviders map[string]map[string]cty.Value // プロバイダ名 -> source/version等
+	Backend           *EnvBackend
+}
+
+// EnvBackend はterraformブロック内のbackendブロック
+type EnvBackend struct {
+	Type  string // s3, gcs等
+	Attrs map[string]cty.Value
+}
+
+// EnvTerragrunt はterragrunt.hclの構成（includeした親の構成を結合したもの）
+type EnvTerragrunt struct {
+	Attrs          map[string]cty.Value // inputs以外のトップレベル属性（skip, iam_role等）
+	Inputs         map[string]cty.Value // inputsのキー -> 値
+	TerraformAttrs map[string]cty.Value // terraformブロックの属性（source等）
+	RemoteState    *EnvBackend          // remote_stateブロック（backendとconfig）。未指定の場合はnil
+}
+
+// EnvMoved はmovedブロック（fromのアドレスで識別）
+type EnvMoved struct {
+	From  string
+	Attrs map[string]cty.Value // to等
+}
+
+// EnvImport はimportブロック（toのアドレスで識別）
+type EnvImport struct {
+	To    string
+	Attrs map[string]cty.Value // id, provider等
+}
+
+// EnvRemoved はremovedブロック（fromのアドレスで識別）
+type EnvRemoved struct {
+	From   string
+	Attrs  map[string]cty.Value
+	Blocks map[string][]*EnvBlock // lifecycle等
+}
+
+// EnvCheck はcheckブロック
+type EnvCheck struct {
+	Name   string
+	Attrs  map[string]cty.Value
+	Blocks map[string][]*EnvBlock // assert, data等
+}
+
 type EnvResources struct {
 	Resources []*EnvResource
 	Modules   []*EnvModule
@@ -48,29 +180,110 @@ type EnvResources struct {
 	Variables []*EnvVariable
 	Outputs   []*EnvOutput
 	DataSources []*EnvData
+	Providers []*EnvProvider
+	Terraform *EnvTerraform // terraformブロックがない場合はnil
+	Terragrunt *EnvTerragrunt // terragrunt.hclがない場合はnil
+	Moved     []*EnvMoved
+	Imports   []*EnvImport
+	Removed   []*EnvRemoved
+	Checks    []*EnvCheck
+
+	index *ResourceIndex // アドレスの索引（Indexで初回に構築する）
+}
+
+// BaselineEntry はベースラインファイルに記録した既知の構成ドリフト
+type BaselineEntry struct {
+	Environment string          `json:"environment"`
+	Resource    string          `json:"resource"`
+	Path        string          `json:"path"`
+	Expected    json.RawMessage `json:"expected"` // 基準環境の値
+	Actual      json.RawMessage `json:"actual"`   // 比較環境の値
+}
+
+// PendingChange はplanに含まれる未適用の変更（no-op以外のresource_changes）
+type PendingChange struct {
+	Address string   // module.app.aws_instance.web[0] 等
+	Actions []string // create, update, delete 等
 }
 
 type EnvBlock struct {
 	Type   string
 	Labels []string
 	Attrs  map[string]cty.Value
+	Blocks map[string][]*EnvBlock // ブロック内のネストブロック（dynamicブロックの content 内のものを含む）
 }
 
 type DiffResult struct {
-	Resource    string
-	Environment string
-	Path        string
-	Expected    cty.Value
-	Actual      cty.Value
-	IsIgnored   bool // 新設計：.tfspecignoreに記載されているかどうか
+	Resource       string
+	Environment    string
+	Path           string
+	Expected       cty.Value
+	Actual         cty.Value
+	IsIgnored      bool   // 新設計：.tfspecignoreに記載されているかどうか
+	Classification string // モジュールのsource/version差分の分類（ModuleChange*）
+	RefChange      string // --base-ref指定時の基準refからの変化（RefChange*）
+	Severity       string // 差分の重要度（Severity*）。未設定の場合は空
+}
+
+// Address は差分の属性パスを含むアドレスを返す（解析できないアドレスは種類なしとしてそのまま扱う）
+func (d *DiffResult) Address() Address {
+	address, err := ParseAddress(d.Resource)
+	if err != nil {
+		address = Address{Name: d.Resource}
+	}
+	address.Path = d.Path
+	return address
 }
 
+// 差分の重要度（低い順）
+const (
+	SeverityInfo     = "info"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// SeverityRank は重要度の順位を返す（未設定・不明な重要度は0）
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityInfo:
+		return 1
+	case SeverityLow:
+		return 2
+	case SeverityMedium:
+		return 3
+	case SeverityHigh:
+		return 4
+	case SeverityCritical:
+		return 5
+	}
+	return 0
+}
+
+// --base-ref指定時の基準refからの差分の変化
+const (
+	RefChangeNew      = "new"      // 基準refにはなかった差分
+	RefChangeChanged  = "changed"  // 基準refにもあったが値が変わった差分
+	RefChangeResolved = "resolved" // 基準refにはあったが解消された差分
+)
+
+// モジュールのsource/version差分の分類
+const (
+	ModuleChangeModule     = "module"     // 別のモジュール
+	ModuleChangeMajor      = "major"      // メジャーバージョンのずれ
+	ModuleChangeMinor      = "minor"      // マイナーバージョンのずれ
+	ModuleChangePatch      = "patch"      // パッチバージョンのずれ
+	ModuleChangeConstraint = "constraint" // バージョン制約と固定バージョンの違い
+)
+
 // TableRow はMarkdownテーブル用のデータ構造
 type TableRow struct {
 	Resource string
 	Path     string
 	Values   map[string]string // 環境名 -> 値
 	Comment  string            // .tfspecignoreのコメント（無視された差分用）
+	Severity string            // 行に含まれる差分の最も高い重要度（未設定の場合は空）
 }
 
 // GroupedTableRow は階層化されたテーブル用のデータ構造
@@ -80,6 +293,7 @@ type GroupedTableRow struct {
 	Path         string    // 属性パス
 	Values       map[string]string // 環境名 -> 値
 	Comment      string    // .tfspecignoreのコメント（無視された差分用）
+	Severity     string    // 差分の重要度（未設定の場合は空）
 	IsFirstInGroup bool    // グループの最初の行かどうか
 	IsFirstInResource bool // リソースの最初の行かどうか
 }
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/Mkamono/tfspec/app/cmd"
+	"github.com/Mkamono/tfspec/app/service"
 )
 
 func main() {
@@ -11,6 +13,11 @@ func main() {
 	rootCmd := app.CreateRootCommand()
 
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		// 検出結果による終了は種類ごとの終了コード、それ以外は解析・設定のエラー
+		var exitErr *service.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		os.Exit(service.ExitCodeError)
 	}
 }
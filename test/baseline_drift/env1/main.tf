@@ -0,0 +1,14 @@
+resource "aws_instance" "web" {
+  ami           = "ami-0abcdef1234567890"
+  instance_type = "t3.small"
+  monitoring    = false
+
+  tags = {
+    Environment = "env1"
+  }
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket        = "logs-env1"
+  force_destroy = true
+}
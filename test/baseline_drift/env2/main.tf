@@ -0,0 +1,14 @@
+resource "aws_instance" "web" {
+  ami           = "ami-0abcdef1234567890"
+  instance_type = "t3.medium"
+  monitoring    = false
+
+  tags = {
+    Environment = "env2"
+  }
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket        = "logs-env2"
+  force_destroy = true
+}
@@ -0,0 +1,14 @@
+resource "aws_instance" "web" {
+  ami           = "ami-0abcdef1234567890"
+  instance_type = "t3.large"
+  monitoring    = true
+
+  tags = {
+    Environment = "env3"
+  }
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket        = "logs-env3"
+  force_destroy = false
+}
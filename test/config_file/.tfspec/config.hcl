@@ -0,0 +1,9 @@
+# 本番相当の環境を比較元とする
+base_env     = "env2"
+exclude_dirs = ["scratch"]
+fail_on      = ["drift", "expired-rules"]
+
+output {
+  max_value_length = 100
+  trim_cell        = true
+}
@@ -0,0 +1,48 @@
+variable "replicas" {
+  type    = number
+  default = 1
+}
+
+variable "azs" {
+  type    = list(string)
+  default = ["ap-northeast-1a"]
+}
+
+locals {
+  buckets = {
+    logs   = "private"
+    assets = "public-read"
+  }
+}
+
+# count による展開
+resource "aws_instance" "web" {
+  count         = var.replicas
+  ami           = "ami-0abcdef1234567890"
+  instance_type = "t3.small"
+
+  tags = {
+    Name        = "web-${count.index}"
+    Environment = "env1"
+  }
+}
+
+# for_each（set）による展開
+resource "aws_subnet" "az" {
+  for_each          = toset(var.azs)
+  availability_zone = each.value
+  cidr_block        = "10.0.1.0/24"
+}
+
+# for_each（map）による展開
+resource "aws_s3_bucket" "this" {
+  for_each = local.buckets
+  bucket   = "env1-${each.key}"
+  acl      = each.value
+}
+
+# 評価できないメタ引数は単一ブロックとして比較
+resource "aws_eip" "nat" {
+  count  = length(data.aws_availability_zones.available.names)
+  domain = "vpc"
+}
@@ -0,0 +1,33 @@
+# 静的なingressブロック
+resource "aws_security_group" "web" {
+  name = "web-sg"
+
+  ingress {
+    from_port   = 80
+    to_port     = 80
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+# for_each が評価できないdynamicブロック（テンプレートを比較）
+resource "aws_security_group" "db" {
+  name = "db-sg"
+
+  dynamic "ingress" {
+    for_each = data.aws_subnet.app[*].cidr_block
+    content {
+      from_port   = 5432
+      to_port     = 5432
+      protocol    = "tcp"
+      cidr_blocks = [ingress.value]
+    }
+  }
+}
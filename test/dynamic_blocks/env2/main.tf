@@ -0,0 +1,32 @@
+locals {
+  web_ports = [80, 443]
+}
+
+# dynamicブロック（評価可能なfor_eachで静的ブロックと同等に展開）
+resource "aws_security_group" "web" {
+  name = "web-sg"
+
+  dynamic "ingress" {
+    for_each = local.web_ports
+    content {
+      from_port   = ingress.value
+      to_port     = ingress.value
+      protocol    = "tcp"
+      cidr_blocks = ["0.0.0.0/0"]
+    }
+  }
+}
+
+resource "aws_security_group" "db" {
+  name = "db-sg"
+
+  dynamic "ingress" {
+    for_each = data.aws_subnet.app[*].cidr_block
+    content {
+      from_port   = 5432
+      to_port     = 5432
+      protocol    = "tcp"
+      cidr_blocks = [ingress.value]
+    }
+  }
+}
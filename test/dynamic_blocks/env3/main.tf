@@ -0,0 +1,34 @@
+variable "web_ports" {
+  type    = list(number)
+  default = [80, 8443]
+}
+
+# iterator を指定したdynamicブロック
+resource "aws_security_group" "web" {
+  name = "web-sg"
+
+  dynamic "ingress" {
+    for_each = var.web_ports
+    iterator = port
+    content {
+      from_port   = port.value
+      to_port     = port.value
+      protocol    = "tcp"
+      cidr_blocks = ["0.0.0.0/0"]
+    }
+  }
+}
+
+resource "aws_security_group" "db" {
+  name = "db-sg"
+
+  dynamic "ingress" {
+    for_each = data.aws_subnet.app[*].cidr_block
+    content {
+      from_port   = 3306
+      to_port     = 3306
+      protocol    = "tcp"
+      cidr_blocks = [ingress.value]
+    }
+  }
+}
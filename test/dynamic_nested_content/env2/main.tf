@@ -0,0 +1,41 @@
+locals {
+  lifecycle_rules = {
+    logs = {
+      prefix = "logs/"
+      transitions = [
+        { days = 30, storage_class = "STANDARD_IA" },
+        { days = 90, storage_class = "GLACIER" },
+      ]
+    }
+  }
+}
+
+# dynamicブロックの content 内に静的なネストブロックとdynamicブロックを持つ
+resource "aws_s3_bucket_lifecycle_configuration" "logs" {
+  bucket = "app-logs"
+
+  dynamic "rule" {
+    for_each = local.lifecycle_rules
+    content {
+      id     = rule.key
+      status = "Enabled"
+
+      filter {
+        prefix = rule.value.prefix
+      }
+
+      dynamic "transition" {
+        for_each = rule.value.transitions
+        content {
+          days          = transition.value.days
+          storage_class = transition.value.storage_class
+        }
+      }
+
+      noncurrent_version_transition {
+        noncurrent_days = 30
+        storage_class   = "STANDARD_IA"
+      }
+    }
+  }
+}
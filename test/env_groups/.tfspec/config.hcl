@@ -0,0 +1,10 @@
+# dev系・prod系の環境をそれぞれグループとして比較する
+base_env = "prod"
+
+group "prod" {
+  envs = ["prod-tokyo", "prod-osaka"]
+}
+
+group "dev" {
+  envs = ["dev-a", "dev-b"]
+}
@@ -0,0 +1,14 @@
+resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+  ami           = "ami-dev"
+
+  tags = {
+    Name   = "web"
+    Region = "ap-northeast-1"
+  }
+}
+
+resource "aws_db_instance" "main" {
+  instance_class = "db.t3.micro"
+  multi_az       = false
+}
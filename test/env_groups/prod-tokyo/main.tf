@@ -0,0 +1,14 @@
+resource "aws_instance" "web" {
+  instance_type = "m5.large"
+  ami           = "ami-prod"
+
+  tags = {
+    Name   = "web"
+    Region = "ap-northeast-1"
+  }
+}
+
+resource "aws_db_instance" "main" {
+  instance_class = "db.r5.large"
+  multi_az       = true
+}
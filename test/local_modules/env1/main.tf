@@ -0,0 +1,5 @@
+module "app" {
+  source        = "../modules/app"
+  env           = "env1"
+  instance_type = "t3.small"
+}
@@ -0,0 +1,5 @@
+module "app" {
+  source        = "../modules/app"
+  env           = "env2"
+  instance_type = "t3.large"
+}
@@ -0,0 +1,5 @@
+module "app" {
+  source        = "../modules/app_v2"
+  env           = "env3"
+  instance_type = "t3.small"
+}
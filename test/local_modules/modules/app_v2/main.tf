@@ -0,0 +1,22 @@
+variable "env" {
+  type = string
+}
+
+variable "instance_type" {
+  type    = string
+  default = "t3.micro"
+}
+
+locals {
+  name = "${var.env}-web"
+}
+
+resource "aws_instance" "web" {
+  ami           = "ami-0abcdef1234567890"
+  instance_type = var.instance_type
+  monitoring    = true
+
+  tags = {
+    Name = local.name
+  }
+}
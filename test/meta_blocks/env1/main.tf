@@ -0,0 +1,20 @@
+resource "aws_instance" "app" {
+  ami           = "ami-0abcdef1234567890"
+  instance_type = "t3.small"
+}
+
+moved {
+  from = aws_instance.web
+  to   = aws_instance.app
+}
+
+check "health" {
+  data "http" "app" {
+    url = "https://env1.example.com/health"
+  }
+
+  assert {
+    condition     = data.http.app.status_code == 200
+    error_message = "app is unhealthy"
+  }
+}
@@ -0,0 +1,25 @@
+resource "aws_instance" "app" {
+  ami           = "ami-0abcdef1234567890"
+  instance_type = "t3.small"
+}
+
+moved {
+  from = aws_instance.web
+  to   = aws_instance.app
+}
+
+import {
+  to = aws_s3_bucket.logs
+  id = "env2-logs"
+}
+
+check "health" {
+  data "http" "app" {
+    url = "https://env2.example.com/health"
+  }
+
+  assert {
+    condition     = data.http.app.status_code == 200
+    error_message = "app is unhealthy"
+  }
+}
@@ -0,0 +1,28 @@
+resource "aws_instance" "app" {
+  ami           = "ami-0abcdef1234567890"
+  instance_type = "t3.small"
+}
+
+moved {
+  from = aws_instance.web
+  to   = aws_instance.application
+}
+
+removed {
+  from = aws_instance.legacy
+
+  lifecycle {
+    destroy = false
+  }
+}
+
+check "health" {
+  data "http" "app" {
+    url = "https://env3.example.com/health"
+  }
+
+  assert {
+    condition     = contains([200, 204], data.http.app.status_code)
+    error_message = "app is unhealthy"
+  }
+}
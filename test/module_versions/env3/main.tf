@@ -0,0 +1,15 @@
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 5.1"
+
+  cidr = "10.0.0.0/16"
+}
+
+module "network" {
+  source = "git::https://github.com/example/terraform-network.git?ref=v2.0.0"
+}
+
+module "dns" {
+  source  = "example/route53/aws"
+  version = "1.0.0"
+}
@@ -0,0 +1,2 @@
+# envs/<region>/<env>/ 構成の環境を自動検出する
+discovery_depth = 3
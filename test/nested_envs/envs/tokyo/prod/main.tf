@@ -0,0 +1,8 @@
+resource "aws_instance" "web" {
+  instance_type = "t3.large"
+  ami           = "ami-0abcdef1234567890"
+
+  tags = {
+    Region = "tokyo"
+  }
+}
@@ -0,0 +1,18 @@
+resource "aws_kms_key" "main" {
+  description         = "main key"
+  enable_key_rotation = true
+}
+
+resource "aws_db_instance" "main" {
+  instance_class      = "db.t3.medium"
+  deletion_protection = true
+
+  tags = {
+    Environment = "env1"
+  }
+}
+
+resource "aws_instance" "web" {
+  instance_type = "t3.small"
+  monitoring    = true
+}
@@ -0,0 +1,13 @@
+resource "aws_db_instance" "main" {
+  instance_class      = "db.t3.large"
+  deletion_protection = false
+
+  tags = {
+    Environment = "env2"
+  }
+}
+
+resource "aws_instance" "web" {
+  instance_type = "t3.large"
+  monitoring    = false
+}
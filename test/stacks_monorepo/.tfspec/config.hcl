@@ -0,0 +1,2 @@
+# スタックごとに環境と.tfspecを持つモノレポ構成
+stacks = ["network", "app"]
@@ -0,0 +1,7 @@
+resource "aws_instance" "web" {
+  instance_type = "t3.large"
+}
+
+resource "aws_cloudwatch_metric_alarm" "high_cpu" {
+  threshold = 80
+}
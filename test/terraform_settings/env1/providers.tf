@@ -0,0 +1,14 @@
+provider "aws" {
+  region = "ap-northeast-1"
+
+  default_tags {
+    tags = {
+      Environment = "env1"
+    }
+  }
+}
+
+provider "aws" {
+  alias  = "osaka"
+  region = "ap-northeast-3"
+}
@@ -0,0 +1,16 @@
+terraform {
+  required_version = ">= 1.5.0"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+
+  backend "s3" {
+    bucket = "tfstate-env1"
+    key    = "app/terraform.tfstate"
+    region = "ap-northeast-1"
+  }
+}
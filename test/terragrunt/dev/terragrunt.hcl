@@ -0,0 +1,21 @@
+include "root" {
+  path = find_in_parent_folders()
+}
+
+terraform {
+  source = "git::https://github.com/example/modules.git//app?ref=v1.2.0"
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+}
+
+locals {
+  env = "dev"
+}
+
+inputs = {
+  env           = local.env
+  instance_type = "t3.micro"
+  vpc_id        = dependency.vpc.outputs.vpc_id
+}
@@ -0,0 +1,29 @@
+include "root" {
+  path           = find_in_parent_folders()
+  merge_strategy = "deep"
+}
+
+terraform {
+  source = "git::https://github.com/example/modules.git//app?ref=v1.1.0"
+}
+
+remote_state {
+  backend = "s3"
+  config = {
+    region = "ap-northeast-3"
+  }
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+}
+
+locals {
+  env = "prod"
+}
+
+inputs = {
+  env           = local.env
+  instance_type = "m5.large"
+  vpc_id        = dependency.vpc.outputs.vpc_id
+}
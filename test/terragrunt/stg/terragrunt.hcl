@@ -0,0 +1,25 @@
+include "root" {
+  path           = find_in_parent_folders()
+  merge_strategy = "deep"
+}
+
+terraform {
+  source = "git::https://github.com/example/modules.git//app?ref=v1.2.0"
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+}
+
+locals {
+  env = "stg"
+}
+
+inputs = {
+  env           = local.env
+  instance_type = "t3.small"
+  vpc_id        = dependency.vpc.outputs.vpc_id
+  tags = {
+    CostCenter = "staging"
+  }
+}
@@ -0,0 +1,20 @@
+# 全環境で共通の設定
+locals {
+  project = "shop"
+}
+
+remote_state {
+  backend = "s3"
+  config = {
+    bucket = "${local.project}-tfstate"
+    key    = "${path_relative_to_include()}/terraform.tfstate"
+    region = "ap-northeast-1"
+  }
+}
+
+inputs = {
+  project = local.project
+  tags = {
+    ManagedBy = "terragrunt"
+  }
+}